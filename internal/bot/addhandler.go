@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// addHandler handles the /add command. It creates a new price agent for the given URL.
+// An optional price can be passed to only get notified when the price drops below it: /add <url> [price]
+func addHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	args := ctx.Args()
+	if len(args) < 2 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /add <url> [Preis]", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	entityURL := args[1]
+
+	var (
+		belowPrice    float64
+		hasBelowPrice bool
+	)
+
+	if len(args) > 2 {
+		priceText := strings.Join(args[2:], " ")
+		priceText = strings.TrimPrefix(priceText, "unter ")
+		priceText = strings.TrimPrefix(priceText, "below ")
+
+		price, parseErr := parsePrice(priceText)
+		if parseErr != nil && !errors.Is(parseErr, ErrOutOfRange) {
+			_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte gib den Preis in der Form '3,99' oder '3.99' an!", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+
+		belowPrice = price
+		hasBelowPrice = true
+	}
+
+	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "typing", nil)
+
+	entity, downloadErr := geizhals.DownloadEntity(entityURL)
+	if downloadErr != nil {
+		log.Printf("addHandler: %s\n", downloadErr)
+
+		if errors.Is(downloadErr, geizhals.ErrInvalidURL) {
+			_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte sende eine valide Geizhals URL!", &gotgbot.SendMessageOpts{})
+		} else {
+			_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Problem beim Abrufen der Daten aufgetreten! Bitte versuche es später erneut", &gotgbot.SendMessageOpts{})
+		}
+
+		return nil
+	}
+
+	location, parseErr := geizhals.LocationFromURL(entityURL)
+	if parseErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte sende eine valide Geizhals URL!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	priceagent, createErr := createPriceagent(ctx.EffectiveUser.Id, ctx.EffectiveChat.Id, entity, location)
+	if createErr != nil {
+		switch {
+		case errors.Is(createErr, ErrPriceagentExists):
+			_, _ = ctx.EffectiveMessage.Reply(bot, "Du hast bereits einen Preisagenten für dieses Produkt!", &gotgbot.SendMessageOpts{})
+			return nil
+		case errors.Is(createErr, ErrMaxPriceagentsReached):
+			_, _ = ctx.EffectiveMessage.Reply(bot, "Du hast bereits die maximale Anzahl an Preisagenten angelegt. Bitte lösche einen Preisagenten, bevor du einen neuen anlegst.", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten!", &gotgbot.SendMessageOpts{})
+
+		return fmt.Errorf("addHandler: %w", createErr)
+	}
+
+	if hasBelowPrice {
+		newNotifSettings := models.NotificationSettings{
+			NotifyBelow: true,
+			BelowPrice:  belowPrice,
+		}
+
+		if dbErr := database.UpdateNotificationSettings(ctx.EffectiveChat.Id, priceagent.ID, newNotifSettings); dbErr != nil {
+			log.Printf("addHandler: %s\n", dbErr)
+		} else {
+			priceagent.NotificationSettings = newNotifSettings
+		}
+	}
+
+	text := fmt.Sprintf("Preisagent für %s wurde erstellt!\nBenachrichtigung: %s", createLink(priceagent.EntityURL(), priceagent.Name), bold(priceagent.NotificationSettings.String()))
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+		},
+	}}
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup:        markup,
+		ParseMode:          "HTML",
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true},
+	})
+	if replyErr != nil {
+		return fmt.Errorf("addHandler: failed to send reply: %w", replyErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
+)
+
+// maxAdminAgentsShown is the maximum number of price agents listed by /admin agents
+const maxAdminAgentsShown = 50
+
+// adminUsage explains the subcommands of the /admin command
+const adminUsage = "Bitte nutze den Befehl wie folgt:\n" +
+	"/admin stats - Statistiken des Bots\n" +
+	"/admin user <id> - Details zu einem Nutzer\n" +
+	"/admin agents <id> - Preisagenten eines Chats\n" +
+	"/admin maintenance [on|off] - Wartungsmodus anzeigen oder umschalten\n" +
+	"/admin tiers - Alle Tarife anzeigen\n" +
+	"/admin tier <name> <limit|delete> - Tarif anlegen, ändern oder löschen\n" +
+	"/admin quota <id> [tier <name>|limit <n>|reset] - Limit eines Chats anzeigen oder ändern\n" +
+	"/admin runupdate - Startet ein Preisupdate\n" +
+	"/admin broadcast <Text> - Sendet eine Nachricht an alle Nutzer"
+
+// isAdmin checks if the given user is one of the admins configured in the config.
+func isAdmin(userID int64) bool {
+	conf, confErr := config.GetConfig()
+	if confErr != nil {
+		log.Println("Error while reading config file: ", confErr)
+		return false
+	}
+
+	return slices.Contains(conf.AdminIDs, userID)
+}
+
+// adminCommandFilter matches the /admin command sent by an admin. The command of other users is handled like any
+// other unknown command, so that the admin commands aren't revealed.
+func adminCommandFilter(msg *gotgbot.Message) bool {
+	if msg.From == nil || !message.Command(msg) || !isAdmin(msg.From.Id) {
+		return false
+	}
+
+	command, _, _ := strings.Cut(strings.Fields(msg.Text)[0], "@")
+
+	return command == "/admin"
+}
+
+// maintenanceStatusText describes the current state of the maintenance mode for admins.
+func maintenanceStatusText(manual, automatic bool) string {
+	switch {
+	case manual && automatic:
+		return "🛠️ Wartungsmodus: aktiv (manuell und automatisch)"
+	case manual:
+		return "🛠️ Wartungsmodus: aktiv (manuell)"
+	case automatic:
+		return "🛠️ Wartungsmodus: aktiv (automatisch wegen Scraper-Fehlern)"
+	}
+
+	return "✅ Wartungsmodus: inaktiv"
+}
+
+// adminStats are the numbers shown by /admin stats
+type adminStats struct {
+	Users       int64
+	Priceagents int64
+	Enabled     int64
+	TypeCounts  map[geizhals.EntityType]int64
+	Scrapes     geizhals.ScrapeStats
+	Maintenance string
+}
+
+// adminStatsText generates the text of /admin stats.
+func adminStatsText(stats adminStats) string {
+	typeNames := []struct {
+		entityType geizhals.EntityType
+		name       string
+	}{
+		{geizhals.Product, "Produkte"},
+		{geizhals.Wishlist, "Wunschlisten"},
+		{geizhals.Category, "Kategorien"},
+		{geizhals.ProductFamily, "Varianten"},
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("📊 Statistiken\n\n")
+	sb.WriteString(fmt.Sprintf("Nutzer: %d\n", stats.Users))
+	sb.WriteString(fmt.Sprintf("Preisagenten: %d (davon pausiert: %d)\n", stats.Priceagents, stats.Priceagents-stats.Enabled))
+
+	for _, typeName := range typeNames {
+		sb.WriteString(fmt.Sprintf("• %s: %d\n", typeName.name, stats.TypeCounts[typeName.entityType]))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nScraper (letzte %d Minuten): %d Abrufe, davon %d fehlgeschlagen (%.1f %%)\n",
+		int(stats.Scrapes.Window.Minutes()), stats.Scrapes.Total, stats.Scrapes.Failures(), stats.Scrapes.FailureRate()*100))
+	sb.WriteString(stats.Maintenance)
+
+	return sb.String()
+}
+
+// adminUserText generates the text of /admin user for the given user.
+func adminUserText(user models.User, ownPriceagents, chatPriceagents, limit int64, limitSource string) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		name = fmt.Sprintf("%s (@%s)", name, user.Username)
+	}
+
+	return fmt.Sprintf("👤 Nutzer %d\n\nName: %s\nSprache: %s\nRegistriert: %s\nPreisagenten im privaten Chat: %d von %d (%s)\nAngelegte Preisagenten (alle Chats): %d",
+		user.ID, name, user.LangCode, user.CreatedAt.Format(statusDateFormat), chatPriceagents, limit, limitSource, ownPriceagents)
+}
+
+// adminAgentsText generates the text of /admin agents listing the given price agents of a chat.
+func adminAgentsText(chatID int64, priceagents []models.PriceAgent) string {
+	if len(priceagents) == 0 {
+		return fmt.Sprintf("Der Chat %d hat keine Preisagenten.", chatID)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Preisagenten von Chat %d (%d):\n", chatID, len(priceagents)))
+
+	for i, priceagent := range priceagents {
+		if i >= maxAdminAgentsShown {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere", len(priceagents)-maxAdminAgentsShown))
+			break
+		}
+
+		sb.WriteString(fmt.Sprintf("\n#%d %s - %s (%s)", priceagent.ID, priceagent.Name, createPrice(priceagent.CurrentPrice(), priceagent.GetCurrency().String()), priceagent.Location))
+
+		if !priceagent.Enabled {
+			sb.WriteString(" ⏸")
+		}
+
+		if status := priceagent.CurrentEntityPrice().Status; status != geizhals.StatusActive {
+			sb.WriteString(fmt.Sprintf(" [%s]", status))
+		}
+	}
+
+	return sb.String()
+}
+
+// adminHandler handles the /admin command, which is only dispatched for admins by the adminCommandFilter.
+func adminHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	args := ctx.Args()
+
+	var text string
+
+	subcommand := ""
+	if len(args) > 1 {
+		subcommand = args[1]
+	}
+
+	switch subcommand {
+	case "stats":
+		text = adminStatsCommand()
+	case "user", "agents":
+		if len(args) < 3 {
+			text = adminUsage
+			break
+		}
+
+		id, parseErr := strconv.ParseInt(args[2], 10, 64)
+		if parseErr != nil {
+			text = "Bitte gib eine gültige ID an!"
+			break
+		}
+
+		if subcommand == "user" {
+			text = adminUserCommand(id)
+		} else {
+			text = adminAgentsCommand(id)
+		}
+	case "maintenance":
+		text = adminMaintenanceCommand(ctx.EffectiveUser.Id, args[2:])
+	case "tiers":
+		text = adminTiersCommand()
+	case "tier":
+		text = adminTierCommand(ctx.EffectiveUser.Id, args[2:])
+	case "quota":
+		text = adminQuotaCommand(ctx.EffectiveUser.Id, args[2:])
+	case "runupdate":
+		text = adminRunUpdateCommand(ctx.EffectiveUser.Id)
+	case "broadcast":
+		return adminBroadcastCommand(bot, ctx)
+	default:
+		text = adminUsage
+	}
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if replyErr != nil {
+		return fmt.Errorf("adminHandler: failed to send reply: %w", replyErr)
+	}
+
+	return nil
+}
+
+// adminStatsCommand collects the statistics of the bot for /admin stats.
+func adminStatsCommand() string {
+	stats := adminStats{
+		Users:       database.GetUserCount(),
+		Priceagents: database.GetPriceAgentCount(),
+		Enabled:     database.GetEnabledPriceAgentCount(),
+		TypeCounts:  make(map[geizhals.EntityType]int64),
+		Scrapes:     geizhals.GetScrapeStats(),
+		Maintenance: maintenanceStatusText(maintenance.status()),
+	}
+
+	for _, entityType := range []geizhals.EntityType{geizhals.Product, geizhals.Wishlist, geizhals.Category, geizhals.ProductFamily} {
+		stats.TypeCounts[entityType] = database.GetPriceAgentCountByType(entityType)
+	}
+
+	return adminStatsText(stats)
+}
+
+// adminUserCommand shows the details of a user for /admin user <id>.
+func adminUserCommand(userID int64) string {
+	user, dbErr := database.GetUserByID(userID)
+	if dbErr != nil {
+		log.Printf("adminUserCommand: %s\n", dbErr)
+		return fmt.Sprintf("Der Nutzer %d wurde nicht gefunden.", userID)
+	}
+
+	limit, limitSource, limitErr := priceagentLimit(userID)
+	if limitErr != nil {
+		log.Printf("adminUserCommand: %s\n", limitErr)
+	}
+
+	return adminUserText(user, database.GetPriceAgentCountForUser(userID), database.GetPriceAgentCountForChat(userID), limit, limitSource)
+}
+
+// adminAgentsCommand lists the price agents of a chat for /admin agents <id>.
+func adminAgentsCommand(chatID int64) string {
+	priceagents, dbErr := database.GetPriceagentsForChat(chatID)
+	if dbErr != nil {
+		return "Es ist ein Fehler beim Laden der Preisagenten aufgetreten!"
+	}
+
+	return adminAgentsText(chatID, priceagents)
+}
+
+// adminMaintenanceCommand shows or toggles the maintenance mode for /admin maintenance [on|off].
+func adminMaintenanceCommand(adminID int64, args []string) string {
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			maintenance.setManual(true)
+		case "off":
+			maintenance.setManual(false)
+		default:
+			return adminUsage
+		}
+
+		log.Printf("Admin %d set maintenance mode '%s'\n", adminID, args[0])
+	}
+
+	return maintenanceStatusText(maintenance.status())
+}
+
+// adminRunUpdateCommand starts a price update in the background for /admin runupdate.
+// The admin is notified once the update is done.
+func adminRunUpdateCommand(adminID int64) string {
+	if !priceUpdateMu.TryLock() {
+		return "Es läuft bereits ein Preisupdate."
+	}
+
+	log.Printf("Admin %d started a price update\n", adminID)
+
+	go func() {
+		defer priceUpdateMu.Unlock()
+
+		start := time.Now()
+		updatePrices()
+
+		notifyAdmins([]int64{adminID}, fmt.Sprintf("✅ Preisupdate abgeschlossen nach %s.", time.Since(start).Round(time.Second)))
+	}()
+
+	return "Preisupdate gestartet."
+}
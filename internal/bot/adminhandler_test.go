@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_adminStatsText(t *testing.T) {
+	stats := adminStats{
+		Users:       12,
+		Priceagents: 30,
+		Enabled:     27,
+		TypeCounts:  map[geizhals.EntityType]int64{geizhals.Product: 20, geizhals.Wishlist: 8, geizhals.Category: 2},
+		Scrapes:     geizhals.ScrapeStats{Window: time.Hour, Total: 40, Outcomes: map[geizhals.ScrapeOutcome]int{geizhals.OutcomeSuccess: 38, geizhals.OutcomeHTTPError: 2}},
+		Maintenance: maintenanceStatusText(false, false),
+	}
+
+	want := "📊 Statistiken\n\n" +
+		"Nutzer: 12\n" +
+		"Preisagenten: 30 (davon pausiert: 3)\n" +
+		"• Produkte: 20\n" +
+		"• Wunschlisten: 8\n" +
+		"• Kategorien: 2\n" +
+		"• Varianten: 0\n" +
+		"\nScraper (letzte 60 Minuten): 40 Abrufe, davon 2 fehlgeschlagen (5.0 %)\n" +
+		"✅ Wartungsmodus: inaktiv"
+
+	if got := adminStatsText(stats); got != want {
+		t.Errorf("adminStatsText() = %q, want %q", got, want)
+	}
+}
+
+func Test_adminAgentsText(t *testing.T) {
+	product := geizhals.Entity{ID: 1, Name: "Produkt", URL: "produkt-a1.html", Type: geizhals.Product, Prices: []geizhals.EntityPrice{{EntityID: 1, Location: "de", Price: 12.34, Currency: geizhals.EUR}}}
+	delisted := geizhals.Entity{ID: 2, Name: "Alt", URL: "alt-a2.html", Type: geizhals.Product, Prices: []geizhals.EntityPrice{{EntityID: 2, Location: "at", Price: 5, Currency: geizhals.EUR, Status: geizhals.StatusDelisted}}}
+
+	tests := []struct {
+		name        string
+		priceagents []models.PriceAgent
+		want        string
+	}{
+		{
+			name: "No price agents",
+			want: "Der Chat 42 hat keine Preisagenten.",
+		},
+		{
+			name: "Paused and delisted",
+			priceagents: []models.PriceAgent{
+				{ID: 3, Name: "Produkt", Location: "de", Entity: product, Enabled: true},
+				{ID: 4, Name: "Alt", Location: "at", Entity: delisted},
+			},
+			want: "Preisagenten von Chat 42 (2):\n\n#3 Produkt - 12.34 € (de)\n#4 Alt - 5.00 € (at) ⏸ [nicht mehr gelistet]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adminAgentsText(42, tt.priceagents); got != tt.want {
+				t.Errorf("adminAgentsText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
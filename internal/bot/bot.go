@@ -2,10 +2,13 @@ package bot
 
 import (
 	"fmt"
+	"html"
 	"log"
 	"net/url"
+	"slices"
 	"time"
 
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
@@ -19,6 +22,8 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/chatmember"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/inlinequery"
 )
 
 var bot *gotgbot.Bot
@@ -27,13 +32,13 @@ var bot *gotgbot.Bot
 func startHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	// Reset user's state to idle
 	userID := ctx.EffectiveUser.Id
-	userstate.UserStates[userID] = userstate.UserState{State: userstate.Idle}
+	userstate.UserStates[userID] = userstate.UserState{State: userstate.Idle, ChatID: ctx.EffectiveChat.Id}
 
 	_, err := ctx.EffectiveMessage.Reply(bot, "Was möchtest du tun?", &gotgbot.SendMessageOpts{
 		ReplyMarkup: gotgbot.InlineKeyboardMarkup{
 			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
-				{Text: "Neuer Preisagent", CallbackData: NewPriceAgentState},
-				{Text: "Meine Preisagenten", CallbackData: ViewPriceAgentState},
+				{Text: "Neuer Preisagent", CallbackData: menuCallback(NewPriceAgentState)},
+				{Text: "Meine Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
 			}},
 		},
 	})
@@ -56,14 +61,25 @@ func viewPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: "📋 Wunschlisten", CallbackData: ShowWishlistPriceagentsState},
-				{Text: "📦 Produkte", CallbackData: ShowProductPriceagentsState},
+				{Text: "📋 Wunschlisten", CallbackData: menuCallback(ShowWishlistPriceagentsState)},
+				{Text: "📦 Produkte", CallbackData: menuCallback(ShowProductPriceagentsState)},
 			},
 			{
-				{Text: "↩️ Zurück", CallbackData: MainMenuState},
+				{Text: "🗂️ Kategorien", CallbackData: menuCallback(ShowCategoryPriceagentsState)},
+				{Text: "🏷️ Tags", CallbackData: menuCallback(ShowTagsState)},
+			},
+			{
+				{Text: "↩️ Zurück", CallbackData: menuCallback(MainMenuState)},
 			},
 		},
 	}
+
+	// Price agents which have been unavailable for a long time are offered for cleanup
+	if gonePriceagents, _ := database.GetGonePriceagentsForChat(ctx.EffectiveChat.Id, time.Now().Add(-cleanupAfter)); len(gonePriceagents) > 0 {
+		cleanupRow := []gotgbot.InlineKeyboardButton{{Text: fmt.Sprintf("🧹 Aufräumen (%d)", len(gonePriceagents)), CallbackData: menuCallback(CleanupPriceagentsState)}}
+		markup.InlineKeyboard = slices.Insert(markup.InlineKeyboard, len(markup.InlineKeyboard)-1, cleanupRow)
+	}
+
 	_, _, err = cbq.Message.EditText(bot, "Welche Preisagenten möchtest du einsehen?", &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
 	if err != nil {
 		return fmt.Errorf("viewPriceagents: failed to edit message text: %w", err)
@@ -74,62 +90,24 @@ func viewPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 // showWishlistPriceagents displays the menu with all wishlist priceagents for the ShowWishlistPriceagentsState callback
 func showWishlistPriceagents(bot *gotgbot.Bot, ctx *ext.Context) error {
-	cbq := ctx.Update.CallbackQuery
-
-	_, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{})
-	if err != nil {
-		return fmt.Errorf("failed to answer start callback query: %w", err)
-	}
-
-	priceagents, _ := database.GetWishlistPriceagentsForUser(ctx.EffectiveUser.Id)
-
-	var messageText string
-	if len(priceagents) == 0 {
-		messageText = "Du hast noch keine Preisagenten für Wunschlisten angelegt!"
-	} else {
-		messageText = "Das sind deine Preisagenten für deine Wunschlisten:"
-	}
-
-	markup := generateEntityKeyboard(priceagents, ShowPriceagentDetailState, 2)
-	_, _, err = cbq.Message.EditText(bot, messageText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
-	if err != nil {
-		return fmt.Errorf("showWishlist: failed to edit message text: %w", err)
-	}
-
-	return nil
+	return showPriceagentList(bot, ctx, ShowWishlistPriceagentsState)
 }
 
 // showProductPriceagents displays the menu with all product priceagents for the ShowProductPriceagentsState callback
 func showProductPriceagents(bot *gotgbot.Bot, ctx *ext.Context) error {
-	cbq := ctx.Update.CallbackQuery
-
-	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
-		return fmt.Errorf("showProductPriceagents: failed to answer callback query: %w", err)
-	}
-
-	productPriceagents, _ := database.GetProductPriceagentsForUser(ctx.EffectiveUser.Id)
-
-	var messageText string
-	if len(productPriceagents) == 0 {
-		messageText = "Du hast noch keine Preisagenten für Produkte angelegt!"
-	} else {
-		messageText = "Das sind deine Preisagenten für deine Produkte:"
-	}
-
-	markup := generateEntityKeyboard(productPriceagents, ShowPriceagentDetailState, 2)
-	_, _, err := cbq.Message.EditText(bot, messageText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
-	if err != nil {
-		return fmt.Errorf("showProduct: failed to edit message text: %w", err)
-	}
+	return showPriceagentList(bot, ctx, ShowProductPriceagentsState)
+}
 
-	return nil
+// showCategoryPriceagents displays the menu with all category priceagents for the ShowCategoryPriceagentsState callback
+func showCategoryPriceagents(bot *gotgbot.Bot, ctx *ext.Context) error {
+	return showPriceagentList(bot, ctx, ShowCategoryPriceagentsState)
 }
 
 // newPriceagentHandler is a callback handler for the NewPriceAgentState callback.
 func newPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
-	conf, confErr := config.GetConfig()
-	if confErr != nil {
-		return fmt.Errorf("newPriceagentHandler: failed to get config: %w", confErr)
+	limit, _, limitErr := priceagentLimit(ctx.EffectiveChat.Id)
+	if limitErr != nil {
+		return fmt.Errorf("newPriceagentHandler: %w", limitErr)
 	}
 
 	cbq := ctx.Update.CallbackQuery
@@ -138,13 +116,13 @@ func newPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return fmt.Errorf("failed to answer start callback query: %w", err)
 	}
 
-	// check if user has capacities for a new priceagent
-	if database.GetPriceAgentCountForUser(ctx.EffectiveUser.Id) >= conf.MaxPriceAgents {
-		text := fmt.Sprintf("Du hast bereits die maximale Anzahl von %d Preisagenten angelegt. Bitte lösche einen Preisagenten, bevor du einen neuen anlegst.", conf.MaxPriceAgents)
+	// check if the chat has capacities for a new priceagent
+	if database.GetPriceAgentCountForChat(ctx.EffectiveChat.Id) >= limit {
+		text := fmt.Sprintf("Du hast bereits die maximale Anzahl von %d Preisagenten angelegt. Bitte lösche einen Preisagenten, bevor du einen neuen anlegst.", limit)
 		markup := gotgbot.InlineKeyboardMarkup{
 			InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 				{
-					{Text: "Zu den Preisagenten", CallbackData: ViewPriceAgentState},
+					{Text: "Zu den Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
 				},
 			},
 		}
@@ -155,14 +133,22 @@ func newPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 		return nil
 	}
-	_, _, err := cbq.Message.EditText(bot, "Bitte sende mir eine URL zu einem Produkt oder einer Wunschliste!", &gotgbot.EditMessageTextOpts{ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}}})
+	markup := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{Text: "🔎 Produkt suchen", CallbackData: menuCallback(SearchProductState)},
+			},
+		},
+	}
+
+	_, _, err := cbq.Message.EditText(bot, "Bitte sende mir eine URL zu einem Produkt, einer Wunschliste oder einer Kategorie mit deinen Filtern! Du kannst auch mehrere URLs, eine Textdatei mit URLs oder die EAN eines Produkts senden.", &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
 	if err != nil {
 		return fmt.Errorf("newPriceagent: failed to edit message text: %w", err)
 	}
 
 	// Set user's State
 	userID := ctx.EffectiveUser.Id
-	userstate.UserStates[userID] = userstate.UserState{State: userstate.CreatePriceagent}
+	userstate.UserStates[userID] = userstate.UserState{State: userstate.CreatePriceagent, ChatID: ctx.EffectiveChat.Id}
 
 	return nil
 }
@@ -178,8 +164,8 @@ func mainMenuHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
-			{Text: "Neuer Preisagent", CallbackData: NewPriceAgentState},
-			{Text: "Meine Preisagenten", CallbackData: ViewPriceAgentState},
+			{Text: "Neuer Preisagent", CallbackData: menuCallback(NewPriceAgentState)},
+			{Text: "Meine Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
 		}},
 	}
 
@@ -195,65 +181,117 @@ func mainMenuHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 func showPriceagentDetail(bot *gotgbot.Bot, ctx *ext.Context) error {
 	cbq := ctx.Update.CallbackQuery
 
-	menu, priceagent, parseErr := parseMenuPriceagent(ctx)
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
 	if parseErr != nil {
 		return fmt.Errorf("showPriceagentDetail: failed to parse callback data: %w", parseErr)
 	}
 
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showPriceagentDetail: failed to answer callback query: %w", err)
+	}
+
+	editedText, markup := priceagentDetailMessage(priceagent, parseListOptions(data))
+
+	switch data.Get(FieldDisplay) {
+	case "", Menu0:
+		_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+		if err != nil {
+			return fmt.Errorf("showPriceagentDetail: failed to edit message text: %w", err)
+		}
+	case Menu1:
+		bot.DeleteMessage(ctx.EffectiveChat.Id, cbq.Message.GetMessageId(), nil)
+
+		_, err := bot.SendMessage(ctx.EffectiveChat.Id, editedText, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+		if err != nil {
+			return fmt.Errorf("showPriceagentDetail: failed to send new message: %w", err)
+		}
+	case Menu2:
+		_, err := bot.SendMessage(ctx.EffectiveChat.Id, editedText, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+		if err != nil {
+			return fmt.Errorf("showPriceagentDetail: failed to send new message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// priceagentDetailMessage generates the text and keyboard of the detail menu of a price agent.
+// The back button returns to the page and sort order of the given list options.
+func priceagentDetailMessage(priceagent models.PriceAgent, listOptions priceagentListOptions) (string, gotgbot.InlineKeyboardMarkup) {
 	var backCallbackData string
 
 	switch {
 	case priceagent.Entity.Type == geizhals.Wishlist:
 		backCallbackData = ShowWishlistPriceagentsState
-	case priceagent.Entity.Type == geizhals.Product:
+	case priceagent.Entity.Type == geizhals.Product, priceagent.Entity.Type == geizhals.ProductFamily:
 		backCallbackData = ShowProductPriceagentsState
+	case priceagent.Entity.Type == geizhals.Category:
+		backCallbackData = ShowCategoryPriceagentsState
 	default:
 		backCallbackData = "invalidType"
 	}
 
-	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
-		return fmt.Errorf("showPriceagentDetail: failed to answer callback query: %w", err)
-	}
-
 	notificationButtonText := fmt.Sprintf("⏰ %s", priceagent.NotificationSettings.String())
 
-	linkName := createLink(priceagent.EntityURL(), priceagent.Entity.Name)
+	linkName := createLink(priceagent.EntityURL(), priceagent.Name)
 	price := priceagent.CurrentEntityPrice()
 	editedText := fmt.Sprintf("%s kostet aktuell %s", linkName, bold(price.String()))
+	switch priceagent.Entity.Type {
+	case geizhals.Category:
+		editedText = fmt.Sprintf("Das günstigste Produkt in %s kostet aktuell %s", linkName, bold(price.String()))
+	case geizhals.ProductFamily:
+		editedText = fmt.Sprintf("Die günstigste Variante von %s kostet aktuell %s", linkName, bold(price.String()))
+	}
+	editedText += productCodesText(priceagent.Entity)
+	editedText += entityStatusText(price)
+	editedText += comparisonTableText(priceagent)
+
+	// Pausing re-renders this menu, so the buttons carry the list options for the back button
+	pauseData := callback.New(PausePriceagentState).With(callback.FieldID, priceagent.ID).With(FieldPage, listOptions.Page).With(FieldSort, listOptions.SortOrder)
+	pauseButton := gotgbot.InlineKeyboardButton{Text: "⏸️ Pausieren", CallbackData: pauseData.MustEncode()}
+	if !priceagent.Enabled {
+		editedText += fmt.Sprintf("\n\n%s Du wirst nicht über Preisänderungen benachrichtigt.", bold("⏸️ Der Preisagent ist pausiert."))
+		pauseData.Action = ResumePriceagentState
+		pauseButton = gotgbot.InlineKeyboardButton{Text: "▶️ Fortsetzen", CallbackData: pauseData.MustEncode()}
+	}
+
+	if len(priceagent.Tags) > 0 {
+		editedText += fmt.Sprintf("\n\n🏷️ %s", html.EscapeString(tagNames(priceagent.Tags)))
+	}
+
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: notificationButtonText, CallbackData: fmt.Sprintf("%s_%d", ChangePriceagentSettingsState, priceagent.ID)},
-				{Text: "📊 Preisverlauf", CallbackData: fmt.Sprintf("%s_%d", ShowPriceHistoryState, priceagent.ID)},
+				{Text: notificationButtonText, CallbackData: menuCallbackWithID(ChangePriceagentSettingsState, priceagent.ID)},
+				{Text: "📊 Preisverlauf", CallbackData: menuCallbackWithID(ShowPriceHistoryState, priceagent.ID)},
 			},
 			{
-				{Text: "❌ Löschen", CallbackData: fmt.Sprintf("%s_%d", DeletePriceagentConfirmState, priceagent.ID)},
-				{Text: "↩️ Zurück", CallbackData: backCallbackData},
+				{Text: "✏️ Umbenennen", CallbackData: menuCallbackWithID(RenamePriceagentState, priceagent.ID)},
+				{Text: "🏷️ Tags", CallbackData: menuCallbackWithID(PriceagentTagsState, priceagent.ID)},
+				pauseButton,
+			},
+			{
+				{Text: "❌ Löschen", CallbackData: menuCallbackWithID(DeletePriceagentConfirmState, priceagent.ID)},
+				{Text: "↩️ Zurück", CallbackData: listCallbackData(backCallbackData, listOptions.Page, listOptions.SortOrder)},
 			},
 		},
 	}
 
-	switch menu.SubMenu {
-	case Menu0:
-		_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
-		if err != nil {
-			return fmt.Errorf("showPriceagentDetail: failed to edit message text: %w", err)
-		}
-	case Menu1:
-		bot.DeleteMessage(ctx.EffectiveChat.Id, cbq.Message.GetMessageId(), nil)
-
-		_, err := bot.SendMessage(ctx.EffectiveChat.Id, editedText, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
-		if err != nil {
-			return fmt.Errorf("showPriceagentDetail: failed to send new message: %w", err)
-		}
-	case Menu2:
-		_, err := bot.SendMessage(ctx.EffectiveChat.Id, editedText, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
-		if err != nil {
-			return fmt.Errorf("showPriceagentDetail: failed to send new message: %w", err)
-		}
+	switch priceagent.Entity.Type {
+	case geizhals.Wishlist:
+		markup.InlineKeyboard[0] = append(markup.InlineKeyboard[0], gotgbot.InlineKeyboardButton{Text: "📋 Inhalt", CallbackData: menuCallbackWithID(ShowWishlistItemsState, priceagent.ID)})
+	case geizhals.Category:
+		// Category listings don't have a price history, their cheapest products are shown instead
+		markup.InlineKeyboard[0][1] = gotgbot.InlineKeyboardButton{Text: fmt.Sprintf("🏆 Top %d", geizhals.CategoryTopN), CallbackData: menuCallbackWithID(ShowWishlistItemsState, priceagent.ID)}
+	case geizhals.Product:
+		markup.InlineKeyboard[0] = append(markup.InlineKeyboard[0], gotgbot.InlineKeyboardButton{Text: "🎨 Varianten", CallbackData: menuCallbackWithID(ShowVariantsState, priceagent.ID)})
+		markup.InlineKeyboard[2] = slices.Insert(markup.InlineKeyboard[2], 1, gotgbot.InlineKeyboardButton{Text: "🌍 Länder", CallbackData: menuCallbackWithID(CompareLocationsState, priceagent.ID)})
+	case geizhals.ProductFamily:
+		// Product families don't have a price history, their members are shown instead
+		markup.InlineKeyboard[0][1] = gotgbot.InlineKeyboardButton{Text: "🎨 Varianten", CallbackData: menuCallbackWithID(ShowWishlistItemsState, priceagent.ID)}
 	}
 
-	return nil
+	return editedText, markup
 }
 
 // changePriceagentSettingsHandler handles the callbacks for the buttons to change the notification
@@ -266,6 +304,10 @@ func changePriceagentSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return fmt.Errorf("changePriceagentSettingsHandler: failed to parse callback data: %w", parseErr)
 	}
 
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
 	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
 		return fmt.Errorf("changePriceagentSettingsHandler: failed to answer callback query: %w", err)
 	}
@@ -276,11 +318,11 @@ func changePriceagentSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: "📉 Unter x€", CallbackData: fmt.Sprintf("%s_%d", SetNotificationBelowState, priceagent.ID)},
-				{Text: "🔔 Immer", CallbackData: fmt.Sprintf("%s_%d", SetNotificationAlwaysState, priceagent.ID)},
+				{Text: "📉 Unter x€", CallbackData: menuCallbackWithID(SetNotificationBelowState, priceagent.ID)},
+				{Text: "🔔 Immer", CallbackData: menuCallbackWithID(SetNotificationAlwaysState, priceagent.ID)},
 			},
 			{
-				{Text: "↩️ Zurück", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, priceagent.ID)},
+				{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
 			},
 		},
 	}
@@ -300,6 +342,10 @@ func deletePriceagentConfirmationHandler(bot *gotgbot.Bot, ctx *ext.Context) err
 		return fmt.Errorf("deletePriceagentConfirmationHandler: failed to parse callback data: %w", parseErr)
 	}
 
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
 	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
 		return fmt.Errorf("deletePriceagentConfirmationHandler: failed to answer callback query: %w", err)
 	}
@@ -309,8 +355,8 @@ func deletePriceagentConfirmationHandler(bot *gotgbot.Bot, ctx *ext.Context) err
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: "❌ Löschen", CallbackData: fmt.Sprintf("%s_%d", DeletePriceagentState, priceagent.ID)},
-				{Text: "↩️ Zurück", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, priceagent.ID)},
+				{Text: "❌ Löschen", CallbackData: menuCallbackWithID(DeletePriceagentState, priceagent.ID)},
+				{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
 			},
 		},
 	}
@@ -326,18 +372,21 @@ func deletePriceagentConfirmationHandler(bot *gotgbot.Bot, ctx *ext.Context) err
 func deletePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	cbq := ctx.Update.CallbackQuery
 
-	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
-		return fmt.Errorf("failed to answer start callback query: %w", err)
-	}
-
 	// Get Priceagent from DB
 	_, priceagent, parseErr := parseMenuPriceagent(ctx)
 	if parseErr != nil {
-		ctx.EffectiveMessage.Reply(bot, "Der Preisagent existiert nicht mehr, vielleicht wurde er schon gelöscht?", &gotgbot.SendMessageOpts{})
 		return fmt.Errorf("deletePriceagentHandler: failed to parse callback data: %w", parseErr)
 	}
 
-	deleteErr := database.DeletePriceAgentForUser(priceagent)
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("failed to answer start callback query: %w", err)
+	}
+
+	deleteErr := database.DeletePriceAgent(priceagent)
 	if deleteErr != nil {
 		ctx.EffectiveMessage.Reply(bot, "Der Preisagent konnte nicht gelöscht werden!", &gotgbot.SendMessageOpts{})
 		return fmt.Errorf("deletePriceagentHandler: failed to delete priceagent from database: %w", deleteErr)
@@ -379,6 +428,12 @@ func newUserHandler(_ *gotgbot.Bot, ctx *ext.Context) error {
 func setCommands() {
 	_, setCommandErr := bot.SetMyCommands([]gotgbot.BotCommand{
 		{Command: "start", Description: "Startmenü des Bots"},
+		{Command: "add", Description: "Legt einen Preisagenten für eine URL an"},
+		{Command: "list", Description: "Zeigt alle deine Preisagenten an"},
+		{Command: "remove", Description: "Löscht einen Preisagenten"},
+		{Command: "price", Description: "Zeigt den aktuellen Preis für eine URL an"},
+		{Command: "search", Description: "Sucht nach Produkten auf Geizhals"},
+		{Command: "lookup", Description: "Sucht ein Produkt anhand der EAN oder Herstellernummer"},
 		{Command: "stop", Description: "Löscht alle Daten und stoppt den Bot"},
 		{Command: "help", Description: "Zeigt die Hilfe an"},
 		{Command: "version", Description: "Zeigt die Version des Bots an"},
@@ -393,31 +448,75 @@ func setCommands() {
 
 // addMessageHandlers adds all the message handlers to the dispatcher. This tells our bot how to handle updates.
 func addMessageHandlers(dispatcher *ext.Dispatcher) {
+	// While in maintenance mode, users only get the maintenance message. Private messages and commands are answered,
+	// other messages in groups are ignored to not spam the group.
+	dispatcher.AddHandlerToGroup(handlers.NewMessage(maintenanceMessageFilter(maintenanceReply), maintenanceHandler), -2)
+	dispatcher.AddHandlerToGroup(handlers.NewMessage(maintenanceMessageFilter(maintenanceIgnore), maintenanceIgnoreHandler), -2)
+	dispatcher.AddHandlerToGroup(handlers.NewCallback(func(cq *gotgbot.CallbackQuery) bool { return inMaintenance(&cq.From) }, maintenanceCallbackHandler), -2)
+	dispatcher.AddHandlerToGroup(handlers.NewInlineQuery(func(iq *gotgbot.InlineQuery) bool { return inMaintenance(&iq.From) }, maintenanceInlineQueryHandler), -2)
+
 	// Text commands
-	dispatcher.AddHandler(handlers.NewCommand("start", maintenanceHandler))
-	dispatcher.AddHandler(handlers.NewCommand("stop", maintenanceHandler))
+	dispatcher.AddHandler(handlers.NewCommand("start", startHandler))
+	dispatcher.AddHandler(handlers.NewCommand("stop", stopHandler))
 	dispatcher.AddHandler(handlers.NewCommand("version", versionHandler))
-	dispatcher.AddHandler(handlers.NewCommand("help", maintenanceHandler))
-
-	// Callback Queries (inline keyboards)
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(StopCancelState), stopHandlerCancel))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(StopConfirmState), stopHandlerConfirm))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(DeletePriceagentConfirmState), deletePriceagentConfirmationHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(DeletePriceagentState), deletePriceagentHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(ShowPriceHistoryState), showPriceHistoryHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(UpdateHistoryGraph1State), updatePriceHistoryGraphHandler))  // Graph 1M
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(UpdateHistoryGraph3State), updatePriceHistoryGraphHandler))  // Graph 3M
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(UpdateHistoryGraph6State), updatePriceHistoryGraphHandler))  // Graph 6M
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(UpdateHistoryGraph12State), updatePriceHistoryGraphHandler)) // Graph 12M
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(SetNotificationBelowState), setNotificationBelowHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(SetNotificationAlwaysState), setNotificationAlwaysHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(ChangePriceagentSettingsState), changePriceagentSettingsHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Prefix(ShowPriceagentDetailState), showPriceagentDetail))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(ShowWishlistPriceagentsState), showWishlistPriceagents))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(ShowProductPriceagentsState), showProductPriceagents))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(ViewPriceAgentState), viewPriceagentsHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(NewPriceAgentState), newPriceagentHandler))
-	dispatcher.AddHandler(handlers.NewCallback(callbackquery.Equal(MainMenuState), mainMenuHandler))
+	dispatcher.AddHandler(handlers.NewCommand("help", helpHandler))
+	dispatcher.AddHandler(handlers.NewMessage(adminCommandFilter, adminHandler))
+	dispatcher.AddHandler(handlers.NewCommand("add", addHandler))
+	dispatcher.AddHandler(handlers.NewCommand("list", listHandler))
+	dispatcher.AddHandler(handlers.NewCommand("remove", removeHandler))
+	dispatcher.AddHandler(handlers.NewCommand("price", priceHandler))
+	dispatcher.AddHandler(handlers.NewCommand("search", searchHandler))
+	dispatcher.AddHandler(handlers.NewCommand("lookup", lookupHandler))
+
+	// Callback Queries (inline keyboards), dispatched by the action of their callback data
+	router := callback.NewRouter()
+	router.Handle(StopCancelState, stopHandlerCancel)
+	router.Handle(StopConfirmState, stopHandlerConfirm)
+	router.Handle(DeletePriceagentConfirmState, deletePriceagentConfirmationHandler)
+	router.Handle(DeletePriceagentState, deletePriceagentHandler)
+	router.Handle(ShowPriceHistoryState, showPriceHistoryHandler)
+	router.Handle(UpdateHistoryGraphState, updatePriceHistoryGraphHandler)
+	router.Handle(SetNotificationBelowState, setNotificationBelowHandler)
+	router.Handle(SetNotificationAlwaysState, setNotificationAlwaysHandler)
+	router.Handle(ChangePriceagentSettingsState, changePriceagentSettingsHandler)
+	router.Handle(RenamePriceagentState, renamePriceagentHandler)
+	router.Handle(PausePriceagentState, pausePriceagentHandler)
+	router.Handle(ResumePriceagentState, pausePriceagentHandler)
+	router.Handle(PriceagentTagsState, priceagentTagsHandler)
+	router.Handle(AddPriceagentTagState, addPriceagentTagHandler)
+	router.Handle(AssignPriceagentTagState, changePriceagentTagHandler)
+	router.Handle(RemovePriceagentTagState, changePriceagentTagHandler)
+	router.Handle(ShowTagsState, showTagsHandler)
+	router.Handle(ShowTagPriceagentsState, showTagPriceagentsHandler)
+	router.Handle(PauseTagPriceagentsState, pauseTagPriceagentsHandler)
+	router.Handle(ResumeTagPriceagentsState, pauseTagPriceagentsHandler)
+	router.Handle(ShowPriceagentDetailState, showPriceagentDetail)
+	router.Handle(ShowWishlistPriceagentsState, showWishlistPriceagents)
+	router.Handle(ShowProductPriceagentsState, showProductPriceagents)
+	router.Handle(ShowCategoryPriceagentsState, showCategoryPriceagents)
+	router.Handle(FilterPriceagentsState, filterPriceagentsHandler)
+	router.Handle(ClearPriceagentsFilterState, clearPriceagentsFilterHandler)
+	router.Handle(ViewPriceAgentState, viewPriceagentsHandler)
+	router.Handle(NewPriceAgentState, newPriceagentHandler)
+	router.Handle(MainMenuState, mainMenuHandler)
+	router.Handle(PreviewCreatePriceagentState, previewCreatePriceagentHandler)
+	router.Handle(PreviewPriceHistoryState, previewPriceHistoryHandler)
+	router.Handle(ShowWishlistItemsState, showWishlistItemsHandler)
+	router.Handle(CreateWishlistItemPriceagentState, createWishlistItemPriceagentHandler)
+	router.Handle(SearchProductState, searchProductHandler)
+	router.Handle(SearchCreatePriceagentState, searchCreatePriceagentHandler)
+	router.Handle(ShowVariantsState, showVariantsHandler)
+	router.Handle(TrackCheapestVariantState, trackCheapestVariantHandler)
+	router.Handle(CompareLocationsState, showCompareLocationsHandler)
+	router.Handle(ToggleCompareLocationState, toggleCompareLocationHandler)
+	router.Handle(CleanupPriceagentsState, cleanupPriceagentsHandler)
+	router.Handle(CleanupPriceagentsConfirmState, cleanupPriceagentsConfirmHandler)
+	router.Handle(ConfirmBroadcastState, confirmBroadcastHandler)
+	router.Handle(CancelBroadcastState, cancelBroadcastHandler)
+	dispatcher.AddHandler(router)
+
+	// Inline queries
+	dispatcher.AddHandler(handlers.NewInlineQuery(inlinequery.All, inlineQueryHandler))
 
 	// Fallback handler for callback queries
 	dispatcher.AddHandler(handlers.NewCallback(callbackquery.All, fallbackCallbackHandler))
@@ -428,6 +527,12 @@ func addMessageHandlers(dispatcher *ext.Dispatcher) {
 	// Any kind of text
 	dispatcher.AddHandler(handlers.NewMessage(message.Text, textHandler))
 
+	// Text files with lists of URLs
+	dispatcher.AddHandler(handlers.NewMessage(message.Document, documentHandler))
+
+	// Membership changes of the bot in groups
+	dispatcher.AddHandler(handlers.NewMyChatMember(chatmember.All, myChatMemberHandler))
+
 	// Store users if not already in database
 	dispatcher.AddHandlerToGroup(handlers.NewCallback(callbackquery.All, newUserHandler), -1)
 	dispatcher.AddHandlerToGroup(handlers.NewMessage(message.Text, newUserHandler), -1)
@@ -444,14 +549,13 @@ func Start(botConfig config.Config) {
 	}
 
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
-		Error: func(_ *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
-			log.Println("an error occurred while handling update:", err.Error())
-			return ext.DispatcherActionNoop
-		},
+		Error: handleUpdateError,
 	})
 
 	updater := ext.NewUpdater(dispatcher, &ext.UpdaterOpts{})
 
+	maintenance.setManual(botConfig.Maintenance.Enabled)
+
 	addMessageHandlers(dispatcher)
 	setCommands()
 
@@ -485,6 +589,10 @@ func Start(botConfig config.Config) {
 
 	log.Printf("Bot has been started as @%s...\n", bot.User.Username)
 
+	resumeBroadcasts()
+
+	go scraperHealthJob()
+
 	if botConfig.Prometheus.Enabled {
 		// Periodically update the metrics from the database
 		go func() {
@@ -510,16 +618,22 @@ func Start(botConfig config.Config) {
 	updater.Idle()
 }
 
-func parseMenuPriceagent(ctx *ext.Context) (models.Menu, models.PriceAgent, error) {
-	menu, parseMenuErr := models.NewMenu(ctx.CallbackQuery.Data)
-	if parseMenuErr != nil {
-		return models.Menu{}, models.PriceAgent{}, fmt.Errorf("invalid callback data: %s", ctx.CallbackQuery.Data)
+// parseMenuPriceagent decodes the callback data of a price agent menu and loads the referenced price agent of the current chat.
+func parseMenuPriceagent(ctx *ext.Context) (callback.Data, models.PriceAgent, error) {
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return callback.Data{}, models.PriceAgent{}, newStaleButtonError(StaleInvalidData, decodeErr)
+	}
+
+	priceagentID, idErr := data.Int(callback.FieldID)
+	if idErr != nil {
+		return callback.Data{}, models.PriceAgent{}, newStaleButtonError(StaleInvalidData, idErr)
 	}
 
-	priceAgent, dbErr := database.GetPriceagentForUserByID(ctx.EffectiveUser.Id, menu.PriceAgentID)
+	priceAgent, dbErr := database.GetPriceagentForChatByID(ctx.EffectiveChat.Id, priceagentID)
 	if dbErr != nil {
-		return models.Menu{}, models.PriceAgent{}, fmt.Errorf("invalid callback data: %s", ctx.CallbackQuery.Data)
+		return callback.Data{}, models.PriceAgent{}, newStaleButtonError(StalePriceagentNotFound, dbErr)
 	}
 
-	return *menu, priceAgent, nil
+	return data, priceAgent, nil
 }
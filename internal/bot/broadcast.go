@@ -0,0 +1,296 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// broadcastInterval paces the messages of a broadcast below the limit of Telegram of about 30 messages per second
+const broadcastInterval = time.Second / 25
+
+// maxBroadcastRetries is the maximum number of retries of a message after Telegram asked to slow down
+const maxBroadcastRetries = 3
+
+// broadcastMu makes sure that only a single broadcast is sent at a time, so that the rate limit holds
+var broadcastMu sync.Mutex
+
+// deliveryResult is the outcome of sending a broadcast to a single user
+type deliveryResult int
+
+const (
+	deliverySent deliveryResult = iota
+	deliveryBlocked
+	deliveryFailed
+)
+
+// deliveryResultFromError classifies the error of sending a broadcast message. Users who blocked the bot or deleted
+// their account are counted as blocked. If Telegram asked to slow down, the duration to wait before retrying is returned.
+func deliveryResultFromError(err error) (deliveryResult, time.Duration) {
+	if err == nil {
+		return deliverySent, 0
+	}
+
+	var telegramErr *gotgbot.TelegramError
+	if errors.As(err, &telegramErr) {
+		switch {
+		case telegramErr.Code == http.StatusTooManyRequests && telegramErr.ResponseParams != nil:
+			return deliveryFailed, time.Duration(telegramErr.ResponseParams.RetryAfter) * time.Second
+		case telegramErr.Code == http.StatusForbidden:
+			return deliveryBlocked, 0
+		}
+	}
+
+	return deliveryFailed, 0
+}
+
+// pendingBroadcastUsers returns the IDs of the users after lastUserID in ascending order, i.e. the users a broadcast
+// wasn't sent to yet.
+func pendingBroadcastUsers(users []models.User, lastUserID int64) []int64 {
+	var userIDs []int64
+
+	for _, user := range users {
+		if user.ID > lastUserID {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+
+	slices.Sort(userIDs)
+
+	return userIDs
+}
+
+// broadcastReportText generates the delivery report sent to the admin after a broadcast finished.
+func broadcastReportText(broadcast models.Broadcast) string {
+	return fmt.Sprintf("📣 Broadcast #%d abgeschlossen:\n\n✅ Gesendet: %d\n🚫 Blockiert: %d\n❌ Fehlgeschlagen: %d",
+		broadcast.ID, broadcast.Sent, broadcast.Blocked, broadcast.Failed)
+}
+
+// sendBroadcastMessage sends the text of a broadcast to a single user. Messages are retried if Telegram asks to slow down.
+func sendBroadcastMessage(userID int64, text string) deliveryResult {
+	for tries := 0; ; tries++ {
+		_, sendErr := bot.SendMessage(userID, text, &gotgbot.SendMessageOpts{ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+
+		result, retryAfter := deliveryResultFromError(sendErr)
+		if retryAfter == 0 || tries >= maxBroadcastRetries {
+			if result == deliveryFailed {
+				log.Printf("Error sending broadcast to user %d: %s\n", userID, sendErr)
+			}
+
+			return result
+		}
+
+		log.Printf("Telegram asked to slow down the broadcast, retrying after %s\n", retryAfter)
+		time.Sleep(retryAfter)
+	}
+}
+
+// runBroadcast sends a running broadcast to all users it wasn't sent to yet and reports the result to the admin.
+// The progress is stored after every user, so that at most one user gets the message twice after a crash.
+func runBroadcast(broadcast models.Broadcast) {
+	broadcastMu.Lock()
+	defer broadcastMu.Unlock()
+
+	userIDs := pendingBroadcastUsers(database.GetAllUsers(), broadcast.LastUserID)
+	log.Printf("Sending broadcast %d to %d users\n", broadcast.ID, len(userIDs))
+
+	ticker := time.NewTicker(broadcastInterval)
+	defer ticker.Stop()
+
+	for _, userID := range userIDs {
+		<-ticker.C
+
+		switch sendBroadcastMessage(userID, broadcast.Text) {
+		case deliverySent:
+			broadcast.Sent++
+		case deliveryBlocked:
+			broadcast.Blocked++
+		default:
+			broadcast.Failed++
+		}
+
+		broadcast.LastUserID = userID
+		if dbErr := database.UpdateBroadcast(broadcast); dbErr != nil {
+			log.Println("Error storing broadcast progress:", dbErr)
+		}
+	}
+
+	broadcast.Status = models.BroadcastDone
+	if dbErr := database.UpdateBroadcast(broadcast); dbErr != nil {
+		log.Println("Error storing broadcast status:", dbErr)
+	}
+
+	log.Printf("Broadcast %d done: %d sent, %d blocked, %d failed\n", broadcast.ID, broadcast.Sent, broadcast.Blocked, broadcast.Failed)
+	notifyAdmins([]int64{broadcast.AdminID}, broadcastReportText(broadcast))
+}
+
+// resumeBroadcasts continues the broadcasts which were interrupted, e.g. by a restart of the bot.
+func resumeBroadcasts() {
+	broadcasts, dbErr := database.GetRunningBroadcasts()
+	if dbErr != nil {
+		log.Println("Error fetching running broadcasts:", dbErr)
+		return
+	}
+
+	for _, broadcast := range broadcasts {
+		log.Printf("Resuming broadcast %d after user %d\n", broadcast.ID, broadcast.LastUserID)
+		notifyAdmins([]int64{broadcast.AdminID}, fmt.Sprintf("📣 Broadcast #%d wurde unterbrochen und wird fortgesetzt.", broadcast.ID))
+
+		go runBroadcast(broadcast)
+	}
+}
+
+// adminBroadcastCommand handles /admin broadcast <text>. The HTML formatted text is sent to the admin as a preview,
+// which has to be confirmed before it's sent to all users.
+func adminBroadcastCommand(bot *gotgbot.Bot, ctx *ext.Context) error {
+	_, text, _ := strings.Cut(ctx.EffectiveMessage.Text, "broadcast")
+	text = strings.TrimSpace(text)
+
+	if text == "" {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /admin broadcast <Text>\n\nDer Text darf HTML-Formatierung enthalten.", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	broadcast := models.Broadcast{AdminID: ctx.EffectiveUser.Id, Text: text, Status: models.BroadcastDraft}
+	if dbErr := database.CreateBroadcast(&broadcast); dbErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("adminBroadcastCommand: %w", dbErr)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{
+		{Text: fmt.Sprintf("📣 An %d Nutzer senden", database.GetUserCount()), CallbackData: menuCallbackWithID(ConfirmBroadcastState, broadcast.ID)},
+		{Text: "↩️ Abbrechen", CallbackData: menuCallbackWithID(CancelBroadcastState, broadcast.ID)},
+	}}}
+
+	// The preview also validates the HTML of the text before it's sent to all users
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}, ReplyMarkup: markup})
+	if sendErr != nil {
+		broadcast.Status = models.BroadcastCanceled
+		if dbErr := database.UpdateBroadcast(broadcast); dbErr != nil {
+			log.Println("Error storing broadcast status:", dbErr)
+		}
+
+		_, _ = ctx.EffectiveMessage.Reply(bot, fmt.Sprintf("Die Vorschau konnte nicht gesendet werden, bitte prüfe die HTML-Formatierung:\n%s", sendErr), &gotgbot.SendMessageOpts{})
+	}
+
+	return nil
+}
+
+// broadcastNotDraftText is shown when the buttons of a broadcast which is no longer a draft are used
+const broadcastNotDraftText = "Dieser Broadcast wurde bereits gesendet oder abgebrochen!"
+
+// parseBroadcastCallback loads the draft broadcast referenced by the callback data of the preview buttons.
+func parseBroadcastCallback(bot *gotgbot.Bot, ctx *ext.Context) (models.Broadcast, bool, error) {
+	cbq := ctx.Update.CallbackQuery
+
+	if !isAdmin(cbq.From.Id) {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Nur Admins können Broadcasts senden!", ShowAlert: true})
+		return models.Broadcast{}, false, nil
+	}
+
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return models.Broadcast{}, false, newStaleButtonError(StaleInvalidData, decodeErr)
+	}
+
+	broadcastID, idErr := data.Int(callback.FieldID)
+	if idErr != nil {
+		return models.Broadcast{}, false, newStaleButtonError(StaleInvalidData, idErr)
+	}
+
+	broadcast, dbErr := database.GetBroadcastByID(broadcastID)
+	if dbErr != nil {
+		return models.Broadcast{}, false, newStaleButtonError(StaleInvalidData, dbErr)
+	}
+
+	if broadcast.Status != models.BroadcastDraft {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: broadcastNotDraftText, ShowAlert: true})
+		return models.Broadcast{}, false, nil
+	}
+
+	return broadcast, true, nil
+}
+
+// confirmBroadcastHandler handles the callback for the button to send a broadcast to all users.
+func confirmBroadcastHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	broadcast, ok, parseErr := parseBroadcastCallback(bot, ctx)
+	if parseErr != nil || !ok {
+		return parseErr
+	}
+
+	// Only a single tap on the buttons may change the draft, even if the buttons are tapped several times quickly
+	changed, dbErr := database.TransitionBroadcastStatus(broadcast.ID, models.BroadcastDraft, models.BroadcastRunning)
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("confirmBroadcastHandler: %w", dbErr)
+	}
+
+	if !changed {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: broadcastNotDraftText, ShowAlert: true})
+		return nil
+	}
+
+	broadcast.Status = models.BroadcastRunning
+
+	log.Printf("Admin %d started broadcast %d\n", cbq.From.Id, broadcast.ID)
+
+	go runBroadcast(broadcast)
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Broadcast gestartet!"}); err != nil {
+		return fmt.Errorf("confirmBroadcastHandler: failed to answer callback query: %w", err)
+	}
+
+	if _, _, err := cbq.Message.EditReplyMarkup(bot, &gotgbot.EditMessageReplyMarkupOpts{}); err != nil {
+		return fmt.Errorf("confirmBroadcastHandler: failed to remove keyboard: %w", err)
+	}
+
+	return nil
+}
+
+// cancelBroadcastHandler handles the callback for the button to discard a broadcast.
+func cancelBroadcastHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	broadcast, ok, parseErr := parseBroadcastCallback(bot, ctx)
+	if parseErr != nil || !ok {
+		return parseErr
+	}
+
+	// Only a single tap on the buttons may change the draft, even if the buttons are tapped several times quickly
+	changed, dbErr := database.TransitionBroadcastStatus(broadcast.ID, models.BroadcastDraft, models.BroadcastCanceled)
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("cancelBroadcastHandler: %w", dbErr)
+	}
+
+	if !changed {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: broadcastNotDraftText, ShowAlert: true})
+		return nil
+	}
+
+	broadcast.Status = models.BroadcastCanceled
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Broadcast abgebrochen!"}); err != nil {
+		return fmt.Errorf("cancelBroadcastHandler: failed to answer callback query: %w", err)
+	}
+
+	if _, _, err := cbq.Message.EditReplyMarkup(bot, &gotgbot.EditMessageReplyMarkupOpts{}); err != nil {
+		return fmt.Errorf("cancelBroadcastHandler: failed to remove keyboard: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func Test_deliveryResultFromError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		want           deliveryResult
+		wantRetryAfter time.Duration
+	}{
+		{name: "Sent", err: nil, want: deliverySent},
+		{name: "Blocked", err: &gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot was blocked by the user"}, want: deliveryBlocked},
+		{name: "Wrapped blocked", err: fmt.Errorf("send: %w", &gotgbot.TelegramError{Code: 403, Description: "Forbidden: user is deactivated"}), want: deliveryBlocked},
+		{name: "Flood control", err: &gotgbot.TelegramError{Code: 429, ResponseParams: &gotgbot.ResponseParameters{RetryAfter: 5}}, want: deliveryFailed, wantRetryAfter: 5 * time.Second},
+		{name: "Chat not found", err: &gotgbot.TelegramError{Code: 400, Description: "Bad Request: chat not found"}, want: deliveryFailed},
+		{name: "Network error", err: errors.New("connection reset"), want: deliveryFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotRetryAfter := deliveryResultFromError(tt.err)
+			if got != tt.want || gotRetryAfter != tt.wantRetryAfter {
+				t.Errorf("deliveryResultFromError() = (%v, %v), want (%v, %v)", got, gotRetryAfter, tt.want, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func Test_pendingBroadcastUsers(t *testing.T) {
+	users := []models.User{{ID: 30}, {ID: 10}, {ID: 20}, {ID: 40}}
+
+	tests := []struct {
+		name       string
+		lastUserID int64
+		want       []int64
+	}{
+		{name: "New broadcast", lastUserID: 0, want: []int64{10, 20, 30, 40}},
+		{name: "Resumed broadcast", lastUserID: 20, want: []int64{30, 40}},
+		{name: "Finished broadcast", lastUserID: 40, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pendingBroadcastUsers(users, tt.lastUserID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pendingBroadcastUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
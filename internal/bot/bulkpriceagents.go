@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	// maxBulkURLs is the maximum number of URLs processed from a single message
+	maxBulkURLs = 50
+	// maxDocumentSize is the maximum size in bytes of a text file containing URLs
+	maxDocumentSize = 64 * 1024
+	// summarySectionReserve is the space kept for each following section of the bulk creation summary
+	summarySectionReserve = 100
+)
+
+// documentHandler handles documents sent by the user. When the user is about to create a new price agent,
+// text files are searched for Geizhals URLs, and price agents are created for all of them.
+func documentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	state, ok := userstate.UserStates[ctx.EffectiveUser.Id]
+	if !ok || state.State != userstate.CreatePriceagent || state.ChatID != ctx.EffectiveChat.Id {
+		return nil
+	}
+
+	document := ctx.EffectiveMessage.Document
+	if document.MimeType != "text/plain" && !strings.HasSuffix(strings.ToLower(document.FileName), ".txt") {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte sende mir eine Textdatei (.txt) mit Geizhals URLs!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	if document.FileSize > maxDocumentSize {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Die Datei ist zu groß!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	content, downloadErr := downloadTextDocument(bot, document)
+	if downloadErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Die Datei konnte nicht geladen werden! Bitte versuche es später erneut", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("documentHandler: %w", downloadErr)
+	}
+
+	urls := geizhals.FindEntityURLs(content)
+	if len(urls) == 0 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "In der Datei wurden keine Geizhals URLs gefunden!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	return bulkCreatePriceagents(bot, ctx, urls)
+}
+
+// downloadTextDocument downloads the given document from the Telegram servers and returns its content.
+func downloadTextDocument(bot *gotgbot.Bot, document *gotgbot.Document) (string, error) {
+	file, getFileErr := bot.GetFile(document.FileId, nil)
+	if getFileErr != nil {
+		return "", fmt.Errorf("failed to get file: %w", getFileErr)
+	}
+
+	httpClient := &http.Client{Timeout: time.Second * 10}
+
+	resp, downloadErr := httpClient.Get(file.URL(bot, nil))
+	if downloadErr != nil {
+		return "", fmt.Errorf("failed to download file: %w", downloadErr)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	}
+
+	content, readErr := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if readErr != nil {
+		return "", fmt.Errorf("failed to read file: %w", readErr)
+	}
+
+	return string(content), nil
+}
+
+// bulkCreatePriceagents creates price agents for all the given URLs and replies with a summary
+// of the created, skipped and failed entries.
+func bulkCreatePriceagents(bot *gotgbot.Bot, ctx *ext.Context, urls []string) error {
+	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "typing", nil)
+
+	var created, skipped, failed []string
+
+	tooMany := 0
+	if len(urls) > maxBulkURLs {
+		tooMany = len(urls) - maxBulkURLs
+		urls = urls[:maxBulkURLs]
+	}
+
+	limitReached := false
+
+	for _, url := range urls {
+		if limitReached {
+			skipped = append(skipped, fmt.Sprintf("%s (Limit erreicht)", html.EscapeString(url)))
+			continue
+		}
+
+		entity, downloadErr := geizhals.DownloadEntity(url)
+		if downloadErr != nil {
+			log.Printf("bulkCreatePriceagents: %s\n", downloadErr)
+			failed = append(failed, html.EscapeString(url))
+
+			continue
+		}
+
+		location, parseErr := geizhals.LocationFromURL(url)
+		if parseErr != nil {
+			log.Printf("bulkCreatePriceagents: %s\n", parseErr)
+			failed = append(failed, html.EscapeString(url))
+
+			continue
+		}
+
+		linkName := createLink(entity.FullURL(location), entity.Name)
+
+		_, createErr := createPriceagent(ctx.EffectiveUser.Id, ctx.EffectiveChat.Id, entity, location)
+		switch {
+		case createErr == nil:
+			created = append(created, linkName)
+		case errors.Is(createErr, ErrPriceagentExists):
+			skipped = append(skipped, fmt.Sprintf("%s (bereits vorhanden)", linkName))
+		case errors.Is(createErr, ErrMaxPriceagentsReached):
+			limitReached = true
+
+			skipped = append(skipped, fmt.Sprintf("%s (Limit erreicht)", linkName))
+		default:
+			log.Printf("bulkCreatePriceagents: %s\n", createErr)
+			failed = append(failed, linkName)
+		}
+	}
+
+	summary := bulkSummaryText(created, skipped, failed, tooMany)
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zu den Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
+		},
+	}}
+
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, summary, &gotgbot.SendMessageOpts{
+		ReplyMarkup:        markup,
+		ParseMode:          "HTML",
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true},
+	})
+	if sendErr != nil {
+		return fmt.Errorf("bulkCreatePriceagents: failed to send summary: %w", sendErr)
+	}
+
+	return nil
+}
+
+// bulkSummaryText generates the summary of the bulk creation. URLs beyond maxBulkURLs are only counted. The lists are
+// shortened if necessary, so that the summary stays below the message size limit.
+func bulkSummaryText(created, skipped, failed []string, tooMany int) string {
+	var sb strings.Builder
+
+	total := len(created) + len(skipped) + len(failed) + tooMany
+	sb.WriteString(bold(fmt.Sprintf("%d von %d Preisagenten wurden erstellt!", len(created), total)))
+	sb.WriteString("\n\n")
+
+	footer := ""
+	if tooMany > 0 {
+		footer = fmt.Sprintf("⏭️ %d weitere Links wurden übersprungen, da maximal %d Links auf einmal verarbeitet werden.", tooMany, maxBulkURLs)
+	}
+
+	sections := []struct {
+		title   string
+		entries []string
+	}{
+		{"✅ Erstellt", created},
+		{"⏭️ Übersprungen", skipped},
+		{"❌ Fehlgeschlagen", failed},
+	}
+
+	for i, section := range sections {
+		// keep some space for the titles of the following sections
+		reserved := 0
+		for _, next := range sections[i+1:] {
+			if len(next.entries) > 0 {
+				reserved += summarySectionReserve
+			}
+		}
+
+		sb.WriteString(summarySection(section.title, section.entries, maxMessageLength-sb.Len()-len(footer)-reserved))
+	}
+
+	sb.WriteString(footer)
+
+	return strings.TrimSpace(sb.String())
+}
+
+// summarySection formats a titled list of entries for the bulk creation summary. Entries which don't fit into the
+// given number of bytes are only counted. Empty lists result in an empty string.
+func summarySection(title string, entries []string, maxLength int) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(bold(title))
+
+	for i, entry := range entries {
+		line := "\n- " + entry
+		remaining := len(entries) - i
+
+		// the last entry doesn't need space for the "more" line
+		moreLine := fmt.Sprintf("\n… und %d weitere", remaining)
+		needed := len(line) + len(moreLine)
+		if remaining == 1 {
+			needed = len(line)
+		}
+
+		if sb.Len()+needed+2 > maxLength {
+			sb.WriteString(moreLine)
+			break
+		}
+
+		sb.WriteString(line)
+	}
+
+	sb.WriteString("\n\n")
+
+	return sb.String()
+}
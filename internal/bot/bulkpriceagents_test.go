@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func Test_bulkSummaryText(t *testing.T) {
+	longEntries := func(prefix string, count int) []string {
+		entries := make([]string, count)
+		for i := range entries {
+			entries[i] = createLink(fmt.Sprintf("https://geizhals.de/%s-a%d.html", prefix, i), fmt.Sprintf("%s Produkt mit einem sehr langen Namen %d", prefix, i))
+		}
+
+		return entries
+	}
+
+	tests := []struct {
+		name         string
+		created      []string
+		skipped      []string
+		failed       []string
+		tooMany      int
+		want         string
+		wantContains []string
+	}{
+		{
+			name:    "Short summary",
+			created: []string{"A", "B"},
+			failed:  []string{"C"},
+			want:    "<b>2 von 3 Preisagenten wurden erstellt!</b>\n\n<b>✅ Erstellt</b>\n- A\n- B\n\n<b>❌ Fehlgeschlagen</b>\n- C",
+		},
+		{
+			name:    "Too many links are counted",
+			created: []string{"A"},
+			tooMany: 150,
+			want:    "<b>1 von 151 Preisagenten wurden erstellt!</b>\n\n<b>✅ Erstellt</b>\n- A\n\n⏭️ 150 weitere Links wurden übersprungen, da maximal 50 Links auf einmal verarbeitet werden.",
+		},
+		{
+			name:         "Long lists are shortened",
+			created:      longEntries("created", 40),
+			skipped:      longEntries("skipped", 10),
+			failed:       longEntries("failed", 10),
+			tooMany:      500,
+			wantContains: []string{"40 von 560", "<b>✅ Erstellt</b>", "<b>⏭️ Übersprungen</b>", "<b>❌ Fehlgeschlagen</b>", "weitere\n", "500 weitere Links"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bulkSummaryText(tt.created, tt.skipped, tt.failed, tt.tooMany)
+			if len(got) > maxMessageLength {
+				t.Errorf("bulkSummaryText() has %d bytes, want at most %d", len(got), maxMessageLength)
+			}
+
+			if tt.want != "" && got != tt.want {
+				t.Errorf("bulkSummaryText() = %q, want %q", got, tt.want)
+			}
+
+			for _, want := range tt.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("bulkSummaryText() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_extractEntityURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *gotgbot.Message
+		want []string
+	}{
+		{
+			name: "No message",
+			msg:  nil,
+			want: nil,
+		},
+		{
+			name: "Text and caption",
+			msg: &gotgbot.Message{
+				Text:    "https://geizhals.de/jabra-elite-85t-a2378831.html",
+				Caption: "https://geizhals.at/?cat=WL-1156092",
+			},
+			want: []string{"https://geizhals.de/jabra-elite-85t-a2378831.html", "https://geizhals.at/?cat=WL-1156092"},
+		},
+		{
+			name: "Hidden text link",
+			msg: &gotgbot.Message{
+				Text:     "Schau mal hier",
+				Entities: []gotgbot.MessageEntity{{Type: "text_link", Offset: 0, Length: 5, Url: "https://geizhals.de/jabra-elite-85t-a2378831.html"}},
+			},
+			want: []string{"https://geizhals.de/jabra-elite-85t-a2378831.html"},
+		},
+		{
+			name: "Duplicates are removed",
+			msg: &gotgbot.Message{
+				Text:     "https://geizhals.de/jabra-elite-85t-a2378831.html",
+				Entities: []gotgbot.MessageEntity{{Type: "text_link", Offset: 0, Length: 5, Url: "https://geizhals.de/jabra-elite-85t-a2378831.html"}},
+			},
+			want: []string{"https://geizhals.de/jabra-elite-85t-a2378831.html"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractEntityURLs(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractEntityURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package callback implements the format of the callback data attached to the inline keyboard buttons of the bot
+// and a router which dispatches callback queries to their handlers based on the decoded action.
+package callback
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+)
+
+// Version is the version of the callback data format. It is encoded as the first byte of the callback data,
+// so that the format can be changed later on while old buttons in existing messages stay readable.
+const Version byte = '1'
+
+// MaxLength is the maximum length of callback data in bytes allowed by Telegram
+const MaxLength = 64
+
+const (
+	fieldSeparator = ";"
+	valueSeparator = "="
+)
+
+// Fields which are available for legacy callback data of the format <menuID>_<submenuID>_<id>[_<extra>]
+const (
+	FieldID    = "i"
+	FieldExtra = "x"
+)
+
+// Fields of the menus to which the components of legacy callback data are converted
+const (
+	FieldLocation = "l"
+	FieldPage     = "p"
+	FieldSort     = "s"
+	FieldList     = "w"
+	FieldTagID    = "t"
+	FieldRange    = "r"
+	FieldDarkMode = "d"
+)
+
+// legacyMenu describes how legacy callback data of a menu is converted to the current format.
+type legacyMenu struct {
+	// action replaces the action of the legacy callback data if set
+	action string
+	// idField is the field of the <id> component, FieldID if empty. The component is dropped if it is "-".
+	idField string
+	// extraField is the field of the <extra> component, FieldExtra if empty
+	extraField string
+	// fields are set in addition to the components
+	fields map[string]string
+}
+
+// legacyMenus maps the legacy menus whose callback data differs from the current format. The price history
+// had a separate menu per date range, which is a field of a single menu today.
+var legacyMenus = map[string]legacyMenu{
+	"m02_00": {idField: FieldPage, extraField: FieldSort},
+	"m02_01": {idField: FieldPage, extraField: FieldSort},
+	"m02_02": {idField: "-", extraField: FieldList},
+	"m02_03": {idField: "-", extraField: FieldList},
+	"m05_01": {extraField: FieldDarkMode, fields: map[string]string{FieldRange: "01"}},
+	"m05_03": {action: "m05_01", extraField: FieldDarkMode, fields: map[string]string{FieldRange: "03"}},
+	"m05_06": {action: "m05_01", extraField: FieldDarkMode, fields: map[string]string{FieldRange: "06"}},
+	"m05_12": {action: "m05_01", extraField: FieldDarkMode, fields: map[string]string{FieldRange: "12"}},
+}
+
+var (
+	ErrTooLong            = errors.New("callback data exceeds 64 bytes")
+	ErrEmpty              = errors.New("callback data is empty")
+	ErrUnknownVersion     = errors.New("unknown callback data version")
+	ErrInvalidFormat      = errors.New("invalid callback data format")
+	ErrInvalidCharacter   = errors.New("callback data contains a reserved character")
+	ErrMissingField       = errors.New("callback data field is missing")
+	ErrInvalidFieldFormat = errors.New("callback data field has an invalid format")
+)
+
+// Data is the decoded callback data of an inline keyboard button. The action identifies the handler
+// of the button, the named fields carry its parameters, e.g. the ID of a price agent.
+type Data struct {
+	Action string
+	Fields map[string]string
+}
+
+// New creates callback data for the given action without any fields.
+func New(action string) Data {
+	return Data{Action: action, Fields: map[string]string{}}
+}
+
+// With returns a copy of the callback data with the given field set to the value.
+func (d Data) With(key string, value any) Data {
+	fields := make(map[string]string, len(d.Fields)+1)
+	for k, v := range d.Fields {
+		fields[k] = v
+	}
+
+	fields[key] = fmt.Sprint(value)
+
+	return Data{Action: d.Action, Fields: fields}
+}
+
+// Get returns the value of a field or an empty string if the field is not set.
+func (d Data) Get(key string) string {
+	return d.Fields[key]
+}
+
+// Int returns the value of a field as integer.
+func (d Data) Int(key string) (int64, error) {
+	value, ok := d.Fields[key]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrMissingField, key)
+	}
+
+	number, parseErr := strconv.ParseInt(value, 10, 64)
+	if parseErr != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidFieldFormat, key)
+	}
+
+	return number, nil
+}
+
+// Encode encodes the callback data in the format <version><action>[;<key>=<value>]...
+// The fields are sorted by their key to get a stable encoding.
+func (d Data) Encode() (string, error) {
+	if d.Action == "" {
+		return "", ErrEmpty
+	}
+
+	if strings.ContainsAny(d.Action, fieldSeparator+valueSeparator) {
+		return "", fmt.Errorf("%w: action %q", ErrInvalidCharacter, d.Action)
+	}
+
+	keys := make([]string, 0, len(d.Fields))
+	for key := range d.Fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var builder strings.Builder
+
+	builder.WriteByte(Version)
+	builder.WriteString(d.Action)
+
+	for _, key := range keys {
+		value := d.Fields[key]
+		if key == "" || strings.ContainsAny(key, fieldSeparator+valueSeparator) || strings.ContainsAny(value, fieldSeparator+valueSeparator) {
+			return "", fmt.Errorf("%w: field %q", ErrInvalidCharacter, key)
+		}
+
+		builder.WriteString(fieldSeparator)
+		builder.WriteString(key)
+		builder.WriteString(valueSeparator)
+		builder.WriteString(value)
+	}
+
+	if builder.Len() > MaxLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrTooLong, builder.Len())
+	}
+
+	return builder.String(), nil
+}
+
+// MustEncode is like Encode but panics if the callback data can't be encoded.
+// It is meant for buttons whose callback data only depends on the program, not on user input.
+func (d Data) MustEncode() string {
+	encoded, err := d.Encode()
+	if err != nil {
+		panic(err)
+	}
+
+	return encoded
+}
+
+// Decode decodes callback data. Besides the current format, legacy callback data of the format
+// <menuID>_<submenuID>[_<id>[_<extra>]] is accepted, so that buttons of old messages keep working.
+func Decode(data string) (Data, error) {
+	if data == "" {
+		return Data{}, ErrEmpty
+	}
+
+	if len(data) > MaxLength {
+		return Data{}, ErrTooLong
+	}
+
+	if data[0] == Version {
+		return decodeV1(data[1:])
+	}
+
+	return decodeLegacy(data)
+}
+
+// decodeV1 decodes the callback data of the first version without the version byte
+func decodeV1(data string) (Data, error) {
+	components := strings.Split(data, fieldSeparator)
+	if components[0] == "" || strings.Contains(components[0], valueSeparator) {
+		return Data{}, ErrInvalidFormat
+	}
+
+	decoded := New(components[0])
+
+	for _, component := range components[1:] {
+		key, value, found := strings.Cut(component, valueSeparator)
+		if !found || key == "" || strings.Contains(value, valueSeparator) {
+			return Data{}, ErrInvalidFormat
+		}
+
+		if _, exists := decoded.Fields[key]; exists {
+			return Data{}, fmt.Errorf("%w: duplicate field %q", ErrInvalidFormat, key)
+		}
+
+		decoded.Fields[key] = value
+	}
+
+	return decoded, nil
+}
+
+// decodeLegacy decodes callback data of the format <menuID>_<submenuID>[_<id>[_<extra>]] as it was used
+// before the callback data was versioned. The components are converted to the fields of the current menus.
+func decodeLegacy(data string) (Data, error) {
+	components := strings.Split(data, "_")
+	if len(components) < 2 || !strings.HasPrefix(components[0], "m") {
+		return Data{}, ErrUnknownVersion
+	}
+
+	if strings.ContainsAny(data, fieldSeparator+valueSeparator) {
+		return Data{}, ErrInvalidFormat
+	}
+
+	if len(components) == 2 {
+		if components[1] == "" {
+			return Data{}, ErrInvalidFormat
+		}
+
+		return New(data), nil
+	}
+
+	menu, parseErr := models.NewMenu(data)
+	if parseErr != nil {
+		return Data{}, fmt.Errorf("%w: %w", ErrInvalidFormat, parseErr)
+	}
+
+	action := menu.ID + "_" + menu.SubMenu
+	mapping := legacyMenus[action]
+
+	if mapping.action != "" {
+		action = mapping.action
+	}
+
+	decoded := New(action)
+
+	switch mapping.idField {
+	case "":
+		decoded = decoded.With(FieldID, menu.PriceAgentID)
+	case "-":
+	default:
+		decoded = decoded.With(mapping.idField, menu.PriceAgentID)
+	}
+
+	if menu.Extra != "" {
+		extraField := FieldExtra
+		if mapping.extraField != "" {
+			extraField = mapping.extraField
+		}
+
+		decoded = decoded.With(extraField, menu.Extra)
+	}
+
+	for key, value := range mapping.fields {
+		decoded = decoded.With(key, value)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,139 @@
+package callback
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestData_Encode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    Data
+		want    string
+		wantErr error
+	}{
+		{"action only", New("m00_00"), "1m00_00", nil},
+		{"single field", New("m03_00").With(FieldID, 123), "1m03_00;i=123", nil},
+		{"fields are sorted", New("m05_01").With("r", "03").With(FieldID, 5).With("d", 1), "1m05_01;d=1;i=5;r=03", nil},
+		{"empty action", New(""), "", ErrEmpty},
+		{"reserved character in action", New("m0;0"), "", ErrInvalidCharacter},
+		{"reserved character in value", New("m00_00").With(FieldExtra, "a=b"), "", ErrInvalidCharacter},
+		{"reserved character in key", New("m00_00").With("a;b", "c"), "", ErrInvalidCharacter},
+		{"too long", New("m00_00").With(FieldExtra, strings.Repeat("x", 60)), "", ErrTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.data.Encode()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Data
+		wantErr error
+	}{
+		{"action only", "1m00_00", New("m00_00"), nil},
+		{"fields", "1m05_01;d=1;i=5", New("m05_01").With("d", 1).With(FieldID, 5), nil},
+		{"empty value", "1m05_01;d=", New("m05_01").With("d", ""), nil},
+		{"legacy menu", "m00_00", New("m00_00"), nil},
+		{"legacy menu with id", "m03_00_123", New("m03_00").With(FieldID, 123), nil},
+		{"legacy menu with extra", "m04_00_5_x", New("m04_00").With(FieldID, 5).With(FieldExtra, "x"), nil},
+		{"legacy product list", "m02_01_2_p", New("m02_01").With(FieldPage, 2).With(FieldSort, "p"), nil},
+		{"legacy wishlist list", "m02_00_0", New("m02_00").With(FieldPage, 0), nil},
+		{"legacy filter", "m02_02_0_w", New("m02_02").With(FieldList, "w"), nil},
+		{"legacy clear filter", "m02_03_0_p", New("m02_03").With(FieldList, "p"), nil},
+		{"legacy price history", "m05_00_5", New("m05_00").With(FieldID, 5), nil},
+		{"legacy graph 1M", "m05_01_5", New("m05_01").With(FieldID, 5).With(FieldRange, "01"), nil},
+		{"legacy graph 3M", "m05_03_5", New("m05_01").With(FieldID, 5).With(FieldRange, "03"), nil},
+		{"legacy graph 6M", "m05_06_5", New("m05_01").With(FieldID, 5).With(FieldRange, "06"), nil},
+		{"legacy graph 12M", "m05_12_5", New("m05_01").With(FieldID, 5).With(FieldRange, "12"), nil},
+		{"legacy graph theme", "m05_03_5_1", New("m05_01").With(FieldID, 5).With(FieldRange, "03").With(FieldDarkMode, 1), nil},
+		{"empty", "", Data{}, ErrEmpty},
+		{"too long", "1" + strings.Repeat("x", 64), Data{}, ErrTooLong},
+		{"unknown version", "2m00_00", Data{}, ErrUnknownVersion},
+		{"missing action", "1;i=5", Data{}, ErrInvalidFormat},
+		{"field without value", "1m03_00;i", Data{}, ErrInvalidFormat},
+		{"duplicate field", "1m03_00;i=1;i=2", Data{}, ErrInvalidFormat},
+		{"legacy menu with invalid id", "m03_00_abc", Data{}, ErrInvalidFormat},
+		{"legacy menu with too many components", "m03_00_1_2_3", Data{}, ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestData_Int(t *testing.T) {
+	data := New("m03_00").With(FieldID, 42).With(FieldExtra, "abc")
+
+	if id, err := data.Int(FieldID); err != nil || id != 42 {
+		t.Errorf("Int() = %d, %v, want 42", id, err)
+	}
+
+	if _, err := data.Int(FieldExtra); !errors.Is(err, ErrInvalidFieldFormat) {
+		t.Errorf("Int() error = %v, want %v", err, ErrInvalidFieldFormat)
+	}
+
+	if _, err := data.Int("missing"); !errors.Is(err, ErrMissingField) {
+		t.Errorf("Int() error = %v, want %v", err, ErrMissingField)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range []string{"1m00_00", "1m05_01;d=1;i=5;r=03", "m03_00_123", "m05_03_5_1", "m02_00", "", "1", "1;=", "m_", "m03_00_1_2_3"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		data, err := Decode(input)
+		if err != nil {
+			return
+		}
+
+		// Everything that can be decoded must survive an encode/decode round trip
+		encoded, encodeErr := data.Encode()
+		if encodeErr != nil {
+			if errors.Is(encodeErr, ErrTooLong) {
+				// Converting legacy data to the current format may add a few bytes
+				return
+			}
+
+			t.Fatalf("Encode() of decoded %q failed: %v", input, encodeErr)
+		}
+
+		if len(encoded) > MaxLength {
+			t.Fatalf("Encode() = %q exceeds %d bytes", encoded, MaxLength)
+		}
+
+		decoded, decodeErr := Decode(encoded)
+		if decodeErr != nil {
+			t.Fatalf("Decode() of encoded %q failed: %v", encoded, decodeErr)
+		}
+
+		if !reflect.DeepEqual(data, decoded) {
+			t.Fatalf("round trip of %q = %+v, want %+v", input, decoded, data)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package callback
+
+import (
+	"fmt"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+)
+
+// contextKey is the key under which the decoded callback data is stored in the update context
+const contextKey = "callback"
+
+// Router dispatches callback queries to the handler registered for the action of their callback data.
+// Actions are matched exactly, hence the order in which the handlers are registered doesn't matter.
+type Router struct {
+	routes map[string]handlers.Response
+}
+
+// NewRouter creates a new router without any routes.
+func NewRouter() *Router {
+	return &Router{routes: map[string]handlers.Response{}}
+}
+
+// Handle registers the handler for the given action. Registering an action twice panics.
+func (r *Router) Handle(action string, response handlers.Response) {
+	if _, exists := r.routes[action]; exists {
+		panic(fmt.Sprintf("callback router: action %q registered twice", action))
+	}
+
+	r.routes[action] = response
+}
+
+// CheckUpdate returns true for callback queries whose data can be decoded and has a registered action.
+func (r *Router) CheckUpdate(_ *gotgbot.Bot, ctx *ext.Context) bool {
+	if ctx.CallbackQuery == nil {
+		return false
+	}
+
+	data, decodeErr := Decode(ctx.CallbackQuery.Data)
+	if decodeErr != nil {
+		return false
+	}
+
+	_, exists := r.routes[data.Action]
+
+	return exists
+}
+
+// HandleUpdate decodes the callback data, stores it in the update context and calls the handler of its action.
+func (r *Router) HandleUpdate(b *gotgbot.Bot, ctx *ext.Context) error {
+	data, decodeErr := Decode(ctx.CallbackQuery.Data)
+	if decodeErr != nil {
+		return fmt.Errorf("callback router: %w", decodeErr)
+	}
+
+	ctx.Data[contextKey] = data
+
+	return r.routes[data.Action](b, ctx)
+}
+
+// Name returns the name of the handler.
+func (r *Router) Name() string {
+	return fmt.Sprintf("callbackrouter_%p", r)
+}
+
+// FromContext returns the decoded callback data of the current update. Callback data which
+// wasn't decoded by the router yet is decoded from the callback query.
+func FromContext(ctx *ext.Context) (Data, error) {
+	if data, ok := ctx.Data[contextKey].(Data); ok {
+		return data, nil
+	}
+
+	if ctx.CallbackQuery == nil {
+		return Data{}, ErrEmpty
+	}
+
+	return Decode(ctx.CallbackQuery.Data)
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"bytes"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// chartFileIDMaxAge is the duration after which cached charts are considered outdated.
+// It matches the caching duration of the price history.
+const chartFileIDMaxAge = 12 * time.Hour
+
+// inlineChartRange is the date range of the charts shared via inline queries
+const inlineChartRange = "03"
+
+// chartKey identifies a rendered chart. Charts of the same entity differ by their date range and theme.
+type chartKey struct {
+	entityID  int64
+	location  string
+	dateRange string
+	darkMode  bool
+}
+
+type cachedChart struct {
+	fileID   string
+	cachedAt time.Time
+}
+
+// chartFileIDCache stores the Telegram file IDs of already uploaded price history charts,
+// so that they can be reused e.g. in inline query results.
+type chartFileIDCache struct {
+	store map[chartKey]cachedChart
+	mutex sync.Mutex
+}
+
+var chartCache = chartFileIDCache{}
+
+func (c *chartFileIDCache) getFileID(key chartKey) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	chart, ok := c.store[key]
+	if !ok || time.Since(chart.cachedAt) > chartFileIDMaxAge {
+		return "", false
+	}
+
+	return chart.fileID, true
+}
+
+func (c *chartFileIDCache) storeFileID(key chartKey, fileID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.store == nil {
+		c.store = make(map[chartKey]cachedChart)
+	}
+
+	c.store[key] = cachedChart{fileID: fileID, cachedAt: time.Now()}
+}
+
+// storeChartFromMessage caches the file ID of the chart contained in the given message.
+func storeChartFromMessage(key chartKey, msg *gotgbot.Message) {
+	if msg == nil || len(msg.Photo) == 0 {
+		return
+	}
+
+	// The last photo size is the one with the highest resolution
+	chartCache.storeFileID(key, msg.Photo[len(msg.Photo)-1].FileId)
+}
+
+// getChartFileID returns the file ID of a price history chart of the inline date range for the given entity and theme.
+// If no chart is cached and a chart cache chat is configured, a new chart is rendered and uploaded to that chat.
+func getChartFileID(bot *gotgbot.Bot, entity geizhals.Entity, location string, darkMode bool) (string, bool) {
+	key := chartKey{entityID: entity.ID, location: location, dateRange: inlineChartRange, darkMode: darkMode}
+	if fileID, ok := chartCache.getFileID(key); ok {
+		return fileID, true
+	}
+
+	conf, confErr := config.GetConfig()
+	if confErr != nil || conf.ChartCacheChatID == 0 {
+		return "", false
+	}
+
+	history, historyErr := geizhals.GetPriceHistory(entity, location)
+	if historyErr != nil || len(history.Response) == 0 {
+		log.Printf("getChartFileID: no price history for '%s': %v\n", entity.Name, historyErr)
+		return "", false
+	}
+
+	priceagent := models.PriceAgent{Name: entity.Name, Entity: entity, Location: location}
+	buffer := bytes.NewBuffer([]byte{})
+	renderChart(priceagent, history, time.Now().AddDate(0, -3, 0), buffer, darkMode)
+
+	msg, sendErr := bot.SendPhoto(conf.ChartCacheChatID, gotgbot.InputFileByReader("chart.png", buffer), &gotgbot.SendPhotoOpts{DisableNotification: true})
+	if sendErr != nil {
+		log.Printf("getChartFileID: failed to upload chart: %s\n", sendErr)
+		return "", false
+	}
+
+	storeChartFromMessage(key, msg)
+
+	return chartCache.getFileID(key)
+}
@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_chartFileIDCache(t *testing.T) {
+	light := chartKey{entityID: 1, location: "de", dateRange: "03", darkMode: false}
+	dark := chartKey{entityID: 1, location: "de", dateRange: "03", darkMode: true}
+	twelveMonths := chartKey{entityID: 1, location: "de", dateRange: "12", darkMode: false}
+	expired := chartKey{entityID: 2, location: "at", dateRange: "03", darkMode: false}
+
+	cache := chartFileIDCache{}
+	cache.storeFileID(light, "light")
+	cache.storeFileID(twelveMonths, "twelve")
+	cache.storeFileID(expired, "expired")
+	cache.store[expired] = cachedChart{fileID: "expired", cachedAt: time.Now().Add(-chartFileIDMaxAge - time.Minute)}
+
+	tests := []struct {
+		name   string
+		key    chartKey
+		want   string
+		wantOk bool
+	}{
+		{"Cached chart", light, "light", true},
+		{"Other theme", dark, "", false},
+		{"Other date range", twelveMonths, "twelve", true},
+		{"Expired chart", expired, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cache.getFileID(tt.key)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("getFileID() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
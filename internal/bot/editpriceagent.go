@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// maxPriceagentNameLength is the maximum number of characters of a price agent name
+const maxPriceagentNameLength = 64
+
+// renamePriceagentHandler handles the callback for the rename button of a price agent. It asks the user for the new name.
+func renamePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("renamePriceagentHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.RenamePriceagent, ChatID: ctx.EffectiveChat.Id, Priceagent: priceagent}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("renamePriceagentHandler: failed to answer callback query: %w", err)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+		},
+	}}
+
+	editedText := fmt.Sprintf("Wie soll der Preisagent für %s heißen?\nAktueller Name: %s", createLink(priceagent.EntityURL(), priceagent.Entity.Name), bold(html.EscapeString(priceagent.Name)))
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("renamePriceagentHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// parseName trims the given name sent by a user and checks that it's neither empty nor longer than maxLength characters.
+func parseName(text string, maxLength int) (string, bool) {
+	name := strings.TrimSpace(text)
+	if name == "" || utf8.RuneCountInString(name) > maxLength {
+		return "", false
+	}
+
+	return name, true
+}
+
+// textRenamePriceagentHandler handles the text message containing the new name of a price agent
+func textRenamePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	state := ctx.Data["state"].(userstate.UserState)
+
+	name, valid := parseName(ctx.EffectiveMessage.Text, maxPriceagentNameLength)
+	if !valid {
+		_, _ = ctx.EffectiveMessage.Reply(bot, fmt.Sprintf("Bitte sende mir einen Namen mit maximal %d Zeichen!", maxPriceagentNameLength), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	dbErr := database.RenamePriceagent(ctx.EffectiveChat.Id, state.Priceagent.ID, name)
+	if dbErr != nil {
+		log.Printf("RenamePriceagent: %s\n", dbErr)
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler beim Umbenennen des Preisagenten aufgetreten!", &gotgbot.SendMessageOpts{})
+
+		return fmt.Errorf("textRenamePriceagentHandler: %w", dbErr)
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.Idle, ChatID: ctx.EffectiveChat.Id}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, state.Priceagent.ID)},
+		},
+	}}
+
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, fmt.Sprintf("Der Preisagent heißt jetzt %s!", bold(html.EscapeString(name))), &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if sendErr != nil {
+		return fmt.Errorf("textRenamePriceagentHandler: failed to send message: %w", sendErr)
+	}
+
+	return nil
+}
+
+// pausePriceagentHandler handles the callbacks for the pause and resume buttons of a price agent.
+// Paused price agents are kept, but they are not checked for price changes.
+func pausePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("pausePriceagentHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	enabled := data.Action == ResumePriceagentState
+
+	dbErr := database.SetPriceagentEnabled(ctx.EffectiveChat.Id, priceagent.ID, enabled)
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("pausePriceagentHandler: %w", dbErr)
+	}
+
+	answerText := "Der Preisagent wurde pausiert!"
+	if enabled {
+		answerText = "Der Preisagent wurde fortgesetzt!"
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: answerText}); err != nil {
+		return fmt.Errorf("pausePriceagentHandler: failed to answer callback query: %w", err)
+	}
+
+	priceagent.Enabled = enabled
+	editedText, markup := priceagentDetailMessage(priceagent, parseListOptions(data))
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("pausePriceagentHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
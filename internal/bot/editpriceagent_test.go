@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_parseName(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      string
+		wantValid bool
+	}{
+		{"Valid name", "Grafikkarte", 50, "Grafikkarte", true},
+		{"Whitespace is trimmed", "  Grafikkarte \n", 50, "Grafikkarte", true},
+		{"Empty name", "", 50, "", false},
+		{"Only whitespace", "   ", 50, "", false},
+		{"Maximum length", strings.Repeat("a", 50), 50, strings.Repeat("a", 50), true},
+		{"Too long", strings.Repeat("a", 51), 50, "", false},
+		{"Characters instead of bytes", strings.Repeat("ä", 50), 50, strings.Repeat("ä", 50), true},
+		{"Emoji counts as character", "🖥️ PC", 5, "🖥️ PC", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, valid := parseName(tt.text, tt.maxLength)
+			if got != tt.want || valid != tt.wantValid {
+				t.Errorf("parseName() = (%q, %v), want (%q, %v)", got, valid, tt.want, tt.wantValid)
+			}
+		})
+	}
+}
+
+func Test_pauseButtonListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		action  string
+	}{
+		{"Pause button of an enabled price agent", true, PausePriceagentState},
+		{"Resume button of a paused price agent", false, ResumePriceagentState},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceagent := models.PriceAgent{ID: 1, Name: "Test", Location: "de", Enabled: tt.enabled, Entity: geizhals.Entity{ID: 1, Type: geizhals.Product}}
+			options := priceagentListOptions{Page: 3, SortOrder: SortByPrice}
+
+			_, markup := priceagentDetailMessage(priceagent, options)
+
+			var pauseData callback.Data
+
+			for _, row := range markup.InlineKeyboard {
+				for _, button := range row {
+					data, decodeErr := callback.Decode(button.CallbackData)
+					if decodeErr == nil && data.Action == tt.action {
+						pauseData = data
+					}
+				}
+			}
+
+			if pauseData.Action == "" {
+				t.Fatalf("no button with action %q found", tt.action)
+			}
+
+			if got, idErr := pauseData.Int(callback.FieldID); idErr != nil || got != priceagent.ID {
+				t.Errorf("price agent ID = %d (%v), want %d", got, idErr, priceagent.ID)
+			}
+
+			if got := parseListOptions(pauseData); got != options {
+				t.Errorf("parseListOptions() = %+v, want %+v", got, options)
+			}
+		})
+	}
+}
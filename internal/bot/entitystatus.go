@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// cleanupAfter is the duration after which price agents of delisted entities or entities without offers are offered for cleanup
+const cleanupAfter = 30 * 24 * time.Hour
+
+// statusDateFormat is the format of the date since which an entity has its current status
+const statusDateFormat = "02.01.2006"
+
+// nextEntityStatus returns the status to store after a check resulted in the given status. Failed checks don't
+// overwrite a known unavailability, so that errors in between don't cause duplicate notifications.
+func nextEntityStatus(oldStatus, checkStatus geizhals.EntityStatus) geizhals.EntityStatus {
+	if checkStatus == geizhals.StatusError && oldStatus.IsGone() {
+		return oldStatus
+	}
+
+	return checkStatus
+}
+
+// entityStatusNotificationMessage generates the text of the notification about an entity which disappeared or
+// reappeared. An empty text is returned for changes of the status which aren't reported, e.g. errors.
+func entityStatusNotificationMessage(priceAgent models.PriceAgent, oldStatus, newStatus geizhals.EntityStatus, price float64) string {
+	entityLink := createLink(priceAgent.EntityURL(), priceAgent.Entity.Name)
+
+	switch {
+	case newStatus == geizhals.StatusDelisted && !oldStatus.IsGone():
+		return fmt.Sprintf("⚠️ %s ist nicht mehr auf Geizhals gelistet. Du wirst benachrichtigt, falls es wieder auftaucht.", entityLink)
+	case newStatus == geizhals.StatusNoOffers && !oldStatus.IsGone():
+		return fmt.Sprintf("⚠️ Für %s gibt es aktuell keine Angebote. Du wirst benachrichtigt, sobald es wieder Angebote gibt.", entityLink)
+	case newStatus == geizhals.StatusActive && oldStatus.IsGone():
+		return fmt.Sprintf("✅ %s ist wieder verfügbar und kostet aktuell %s", entityLink, bold(createPrice(price, priceAgent.GetCurrency().String())))
+	}
+
+	return ""
+}
+
+// updateEntityStatus stores the status of the entity of the price agent and notifies the chat once the entity
+// disappears or reappears. It returns true if a notification was sent.
+func updateEntityStatus(priceAgent models.PriceAgent, checkStatus geizhals.EntityStatus, price float64) bool {
+	oldStatus := priceAgent.CurrentEntityPrice().Status
+
+	newStatus := nextEntityStatus(oldStatus, checkStatus)
+	if newStatus == oldStatus {
+		return false
+	}
+
+	log.Printf("Status of entity %d (%s) changed from '%s' to '%s'\n", priceAgent.EntityID, priceAgent.Location, oldStatus, newStatus)
+
+	if dbErr := database.UpdateEntityStatus(priceAgent.EntityID, priceAgent.Location, newStatus); dbErr != nil {
+		log.Println("Error updating entity status:", dbErr)
+	}
+
+	notificationText := entityStatusNotificationMessage(priceAgent, oldStatus, newStatus, price)
+	if notificationText == "" {
+		return false
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceAgent.ID)},
+			},
+		},
+	}
+
+	if newStatus.IsGone() {
+		markup.InlineKeyboard[0] = append(markup.InlineKeyboard[0], gotgbot.InlineKeyboardButton{Text: "❌ Löschen", CallbackData: menuCallbackWithID(DeletePriceagentConfirmState, priceAgent.ID)})
+	}
+
+	sendNotificationWithMarkup(priceAgent, notificationText, markup)
+
+	return true
+}
+
+// entityStatusText describes the unavailability of the entity of a price agent for the detail menu.
+func entityStatusText(price geizhals.EntityPrice) string {
+	if !price.Status.IsGone() {
+		return ""
+	}
+
+	if price.StatusSince.IsZero() {
+		return fmt.Sprintf("\n\n⚠️ Status: %s", bold(price.Status.String()))
+	}
+
+	return fmt.Sprintf("\n\n⚠️ Status: %s seit %s", bold(price.Status.String()), price.StatusSince.Format(statusDateFormat))
+}
+
+// cleanupPriceagentsMessage generates the text and keyboard of the menu listing the price agents which have been
+// unavailable for a long time.
+func cleanupPriceagentsMessage(priceagents []models.PriceAgent) (string, gotgbot.InlineKeyboardMarkup) {
+	backButton := gotgbot.InlineKeyboardButton{Text: "↩️ Zurück", CallbackData: menuCallback(ViewPriceAgentState)}
+
+	if len(priceagents) == 0 {
+		return "Es gibt keine Preisagenten zum Aufräumen.", gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{backButton}}}
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Diese Preisagenten sind seit über %d Tagen nicht mehr verfügbar:\n", int(cleanupAfter.Hours()/24)))
+
+	for _, priceagent := range priceagents {
+		price := priceagent.CurrentEntityPrice()
+		sb.WriteString(fmt.Sprintf("\n• %s (%s seit %s)", createLink(priceagent.EntityURL(), priceagent.Name), price.Status.String(), price.StatusSince.Format(statusDateFormat)))
+	}
+
+	sb.WriteString("\n\nMöchtest du sie löschen?")
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: fmt.Sprintf("🗑️ Alle %d löschen", len(priceagents)), CallbackData: menuCallback(CleanupPriceagentsConfirmState)},
+		},
+		{backButton},
+	}}
+
+	return sb.String(), markup
+}
+
+// cleanupPriceagentsHandler handles the callback for the cleanup button of the price agent menu.
+func cleanupPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	priceagents, dbErr := database.GetGonePriceagentsForChat(ctx.EffectiveChat.Id, time.Now().Add(-cleanupAfter))
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("cleanupPriceagentsHandler: %w", dbErr)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("cleanupPriceagentsHandler: failed to answer callback query: %w", err)
+	}
+
+	editedText, markup := cleanupPriceagentsMessage(priceagents)
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if err != nil {
+		return fmt.Errorf("cleanupPriceagentsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupPriceagentsConfirmHandler handles the callback for the button to delete all price agents which have been
+// unavailable for a long time.
+func cleanupPriceagentsConfirmHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	priceagents, dbErr := database.GetGonePriceagentsForChat(ctx.EffectiveChat.Id, time.Now().Add(-cleanupAfter))
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("cleanupPriceagentsConfirmHandler: %w", dbErr)
+	}
+
+	deleted := 0
+
+	for _, priceagent := range priceagents {
+		if deleteErr := database.DeletePriceAgent(priceagent); deleteErr != nil {
+			log.Printf("cleanupPriceagentsConfirmHandler: failed to delete priceagent %d: %s\n", priceagent.ID, deleteErr)
+			continue
+		}
+
+		deleted++
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("cleanupPriceagentsConfirmHandler: failed to answer callback query: %w", err)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zu den Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
+		},
+	}}
+
+	_, _, err := cbq.Message.EditText(bot, fmt.Sprintf("Es wurden %d Preisagenten gelöscht!", deleted), &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
+	if err != nil {
+		return fmt.Errorf("cleanupPriceagentsConfirmHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_nextEntityStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldStatus   geizhals.EntityStatus
+		checkStatus geizhals.EntityStatus
+		want        geizhals.EntityStatus
+	}{
+		{name: "Delisted", oldStatus: geizhals.StatusActive, checkStatus: geizhals.StatusDelisted, want: geizhals.StatusDelisted},
+		{name: "Reappeared", oldStatus: geizhals.StatusNoOffers, checkStatus: geizhals.StatusActive, want: geizhals.StatusActive},
+		{name: "Error keeps delisted", oldStatus: geizhals.StatusDelisted, checkStatus: geizhals.StatusError, want: geizhals.StatusDelisted},
+		{name: "Error keeps no offers", oldStatus: geizhals.StatusNoOffers, checkStatus: geizhals.StatusError, want: geizhals.StatusNoOffers},
+		{name: "Error of active entity", oldStatus: geizhals.StatusActive, checkStatus: geizhals.StatusError, want: geizhals.StatusError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextEntityStatus(tt.oldStatus, tt.checkStatus); got != tt.want {
+				t.Errorf("nextEntityStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_entityStatusNotificationMessage(t *testing.T) {
+	priceAgent := models.PriceAgent{
+		Location: "de",
+		Entity:   geizhals.Entity{ID: 1, Name: "Produkt", URL: "produkt-a1.html", Type: geizhals.Product},
+	}
+	link := `<a href="https://geizhals.de/produkt-a1.html">Produkt</a>`
+
+	tests := []struct {
+		name      string
+		oldStatus geizhals.EntityStatus
+		newStatus geizhals.EntityStatus
+		want      string
+	}{
+		{
+			name:      "Delisted",
+			oldStatus: geizhals.StatusActive,
+			newStatus: geizhals.StatusDelisted,
+			want:      "⚠️ " + link + " ist nicht mehr auf Geizhals gelistet. Du wirst benachrichtigt, falls es wieder auftaucht.",
+		},
+		{
+			name:      "No offers after error",
+			oldStatus: geizhals.StatusError,
+			newStatus: geizhals.StatusNoOffers,
+			want:      "⚠️ Für " + link + " gibt es aktuell keine Angebote. Du wirst benachrichtigt, sobald es wieder Angebote gibt.",
+		},
+		{
+			name:      "Reappeared",
+			oldStatus: geizhals.StatusDelisted,
+			newStatus: geizhals.StatusActive,
+			want:      "✅ " + link + " ist wieder verfügbar und kostet aktuell <b>12.34 €</b>",
+		},
+		{
+			name:      "Delisted after no offers",
+			oldStatus: geizhals.StatusNoOffers,
+			newStatus: geizhals.StatusDelisted,
+		},
+		{
+			name:      "Recovered from error",
+			oldStatus: geizhals.StatusError,
+			newStatus: geizhals.StatusActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entityStatusNotificationMessage(priceAgent, tt.oldStatus, tt.newStatus, 12.34); got != tt.want {
+				t.Errorf("entityStatusNotificationMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
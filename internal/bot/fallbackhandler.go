@@ -1,24 +1,26 @@
 package bot
 
 import (
-	"fmt"
 	"log"
 
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 )
 
-// fallbackCallbackHandler logs all the callback queries that are not handled by the other handlers.
-// That should barely ever happen.
+// fallbackCallbackHandler handles all the callback queries that are not handled by the callback router.
+// These are buttons of old messages whose callback data can't be decoded or whose action doesn't exist anymore.
 func fallbackCallbackHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	cbq := ctx.Update.CallbackQuery
 	log.Printf("fallbackCallbackHandler - handled data: %s\n", cbq.Data)
 
-	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
-		return fmt.Errorf("failed to answer start callback query: %w", err)
+	category := StaleUnknownAction
+	if _, decodeErr := callback.Decode(cbq.Data); decodeErr != nil {
+		category = StaleInvalidData
 	}
 
-	return nil
+	return handleStaleButton(bot, ctx, category)
 }
 
 // fallbackCommandHandler handles messates with unknown commands. It does not reply to the user.
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// canManagePriceagents checks if the user of the current update may edit or delete the price agents of the chat.
+// In private chats this is always the case, in groups only administrators may manage the shared price agents.
+func canManagePriceagents(bot *gotgbot.Bot, ctx *ext.Context) (bool, error) {
+	if ctx.EffectiveChat.Type == gotgbot.ChatTypePrivate {
+		return true, nil
+	}
+
+	member, getMemberErr := bot.GetChatMember(ctx.EffectiveChat.Id, ctx.EffectiveUser.Id, nil)
+	if getMemberErr != nil {
+		return false, fmt.Errorf("canManagePriceagents: failed to get chat member: %w", getMemberErr)
+	}
+
+	return isManagingMemberStatus(member.GetStatus()), nil
+}
+
+// isManagingMemberStatus checks if a group member with the given status may manage the price agents of the group.
+func isManagingMemberStatus(status string) bool {
+	switch status {
+	case gotgbot.ChatMemberStatusOwner, gotgbot.ChatMemberStatusAdministrator:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkManagePermission answers the callback query with an explanation if the user is not allowed to manage
+// the price agents of the chat. It returns true if the user has the permission.
+func checkManagePermission(bot *gotgbot.Bot, ctx *ext.Context) bool {
+	allowed, permissionErr := canManagePriceagents(bot, ctx)
+	if permissionErr != nil {
+		log.Println(permissionErr)
+	}
+
+	if allowed {
+		return true
+	}
+
+	text := "Nur Administratoren der Gruppe können Preisagenten bearbeiten oder löschen!"
+	if ctx.CallbackQuery != nil {
+		_, _ = ctx.CallbackQuery.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: text, ShowAlert: true})
+	} else {
+		_, _ = ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{})
+	}
+
+	return false
+}
+
+// myChatMemberHandler handles changes of the bot's own membership in chats.
+// When the bot gets removed from a group, the price agents of that group are deleted.
+func myChatMemberHandler(_ *gotgbot.Bot, ctx *ext.Context) error {
+	update := ctx.MyChatMember
+	if !botRemovedFromGroup(update) {
+		return nil
+	}
+
+	log.Printf("Bot was removed from chat %d, deleting its price agents\n", update.Chat.Id)
+
+	if deleteErr := database.DeletePriceagentsForChat(update.Chat.Id); deleteErr != nil {
+		return fmt.Errorf("myChatMemberHandler: failed to delete price agents: %w", deleteErr)
+	}
+
+	return nil
+}
+
+// botRemovedFromGroup checks if the given update of the bot's membership removes the bot from a group.
+// Private chats are ignored, their price agents are kept when the user blocks the bot.
+func botRemovedFromGroup(update *gotgbot.ChatMemberUpdated) bool {
+	if update == nil || update.Chat.Type == gotgbot.ChatTypePrivate {
+		return false
+	}
+
+	switch update.NewChatMember.GetStatus() {
+	case gotgbot.ChatMemberStatusLeft, gotgbot.ChatMemberStatusBanned:
+		return true
+	default:
+		return false
+	}
+}
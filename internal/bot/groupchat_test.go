@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+func Test_isManagingMemberStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{gotgbot.ChatMemberStatusOwner, true},
+		{gotgbot.ChatMemberStatusAdministrator, true},
+		{gotgbot.ChatMemberStatusMember, false},
+		{gotgbot.ChatMemberStatusRestricted, false},
+		{gotgbot.ChatMemberStatusLeft, false},
+		{gotgbot.ChatMemberStatusBanned, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := isManagingMemberStatus(tt.status); got != tt.want {
+				t.Errorf("isManagingMemberStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_botRemovedFromGroup(t *testing.T) {
+	group := gotgbot.Chat{Id: -100, Type: gotgbot.ChatTypeSupergroup}
+	private := gotgbot.Chat{Id: 123, Type: gotgbot.ChatTypePrivate}
+
+	tests := []struct {
+		name   string
+		update *gotgbot.ChatMemberUpdated
+		want   bool
+	}{
+		{
+			name:   "No update",
+			update: nil,
+			want:   false,
+		},
+		{
+			name:   "Left group",
+			update: &gotgbot.ChatMemberUpdated{Chat: group, NewChatMember: gotgbot.ChatMemberLeft{}},
+			want:   true,
+		},
+		{
+			name:   "Banned from group",
+			update: &gotgbot.ChatMemberUpdated{Chat: group, NewChatMember: gotgbot.ChatMemberBanned{}},
+			want:   true,
+		},
+		{
+			name:   "Added to group",
+			update: &gotgbot.ChatMemberUpdated{Chat: group, NewChatMember: gotgbot.ChatMemberMember{}},
+			want:   false,
+		},
+		{
+			name:   "Promoted to admin",
+			update: &gotgbot.ChatMemberUpdated{Chat: group, NewChatMember: gotgbot.ChatMemberAdministrator{}},
+			want:   false,
+		},
+		{
+			name:   "Blocked in private chat",
+			update: &gotgbot.ChatMemberUpdated{Chat: private, NewChatMember: gotgbot.ChatMemberBanned{}},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := botRemovedFromGroup(tt.update); got != tt.want {
+				t.Errorf("botRemovedFromGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_canManagePriceagents_privateChat(t *testing.T) {
+	// Private chats don't need to query the chat member, so no bot is required
+	ctx := &ext.Context{EffectiveChat: &gotgbot.Chat{Id: 123, Type: gotgbot.ChatTypePrivate}, EffectiveUser: &gotgbot.User{Id: 123}}
+
+	allowed, err := canManagePriceagents(nil, ctx)
+	if err != nil || !allowed {
+		t.Errorf("canManagePriceagents() = (%v, %v), want (true, nil)", allowed, err)
+	}
+}
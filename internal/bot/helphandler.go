@@ -12,10 +12,19 @@ func helpHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	helpMessage := "Du brauchst Hilfe? Probiere folgende Befehle:\n" +
 		"\n" +
 		"/start - Startmenü\n" +
+		"/add <url> [Preis] - Legt einen Preisagenten an\n" +
+		"/list [Seite] - Zeigt alle deine Preisagenten\n" +
+		"/remove <id|name> - Löscht einen Preisagenten\n" +
+		"/price <url> - Zeigt den aktuellen Preis\n" +
+		"/search <Suchbegriff> - Sucht nach Produkten\n" +
+		"/lookup <EAN|Herstellernummer> - Sucht ein Produkt anhand seiner Nummer\n" +
 		"/help - Zeigt diese Hilfe\n" +
 		"/stop - Löscht alle deine Daten und beendet den Bot\n" +
 		"/version - Zeigt die aktuelle Version des Bots"
 	_, err := ctx.Message.Reply(bot, helpMessage, nil)
+	if err != nil {
+		return fmt.Errorf("helpHandler: %w", err)
+	}
 
-	return fmt.Errorf("helpHandler: %w", err)
+	return nil
 }
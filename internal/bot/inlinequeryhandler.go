@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// maxInlineResults is the maximum number of results Telegram accepts for a single inline query
+const maxInlineResults = 50
+
+// inlineQueryHandler answers inline queries. Users can either query a Geizhals URL or the name of one of their
+// price agents. Each match is returned as an article with the current price and - if available - a price chart.
+func inlineQueryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	inlineQuery := ctx.InlineQuery
+	query := strings.TrimSpace(inlineQuery.Query)
+
+	var results []gotgbot.InlineQueryResult
+
+	// Charts are shared in the theme the user chose for their own charts
+	darkMode := database.GetDarkmode(ctx.EffectiveUser.Id)
+
+	if urls := geizhals.FindEntityURLs(query); len(urls) > 0 {
+		entity, downloadErr := geizhals.DownloadEntity(urls[0])
+		if downloadErr != nil {
+			log.Printf("inlineQueryHandler: %s\n", downloadErr)
+		}
+
+		location, locationErr := geizhals.LocationFromURL(urls[0])
+		if downloadErr == nil && locationErr == nil {
+			results = append(results, inlineEntityResults(bot, entity, location, darkMode, true)...)
+		}
+	} else {
+		// Inline queries aren't bound to a chat, so only the private price agents of the user are searched
+		priceagents, priceagentsErr := database.GetPriceagentsForChat(ctx.EffectiveUser.Id)
+		if priceagentsErr != nil {
+			return fmt.Errorf("inlineQueryHandler: %w", priceagentsErr)
+		}
+
+		query = strings.ToLower(query)
+
+		for _, priceagent := range priceagents {
+			if !strings.Contains(strings.ToLower(priceagent.Name), query) {
+				continue
+			}
+
+			results = append(results, inlineEntityResults(bot, priceagent.Entity, priceagent.Location, darkMode, false)...)
+		}
+	}
+
+	if len(results) > maxInlineResults {
+		results = results[:maxInlineResults]
+	}
+
+	cacheTime := int64(60)
+
+	_, answerErr := inlineQuery.Answer(bot, results, &gotgbot.AnswerInlineQueryOpts{IsPersonal: true, CacheTime: &cacheTime})
+	if answerErr != nil {
+		return fmt.Errorf("inlineQueryHandler: failed to answer inline query: %w", answerErr)
+	}
+
+	return nil
+}
+
+// inlineEntityResults creates the inline query results for an entity. Next to an article containing the name,
+// the price and the link of the entity, a cached price chart is added if available. If renderMissingChart is true,
+// a missing chart gets rendered on demand. Only charts of the inline date range in the given theme are used.
+func inlineEntityResults(bot *gotgbot.Bot, entity geizhals.Entity, location string, darkMode, renderMissingChart bool) []gotgbot.InlineQueryResult {
+	price := entity.GetPrice(location)
+	messageText := fmt.Sprintf("%s kostet aktuell %s", createLink(entity.FullURL(location), entity.Name), bold(price.String()))
+
+	results := []gotgbot.InlineQueryResult{
+		gotgbot.InlineQueryResultArticle{
+			Id:          fmt.Sprintf("a_%d_%s", entity.ID, location),
+			Title:       entity.Name,
+			Description: price.String(),
+			Url:         entity.FullURL(location),
+			InputMessageContent: gotgbot.InputTextMessageContent{
+				MessageText: messageText,
+				ParseMode:   "HTML",
+			},
+		},
+	}
+
+	var (
+		fileID string
+		ok     bool
+	)
+
+	if renderMissingChart {
+		fileID, ok = getChartFileID(bot, entity, location, darkMode)
+	} else {
+		fileID, ok = chartCache.getFileID(chartKey{entityID: entity.ID, location: location, dateRange: inlineChartRange, darkMode: darkMode})
+	}
+
+	if ok {
+		results = append(results, gotgbot.InlineQueryResultCachedPhoto{
+			Id:          fmt.Sprintf("p_%d_%s", entity.ID, location),
+			PhotoFileId: fileID,
+			Title:       entity.Name,
+			Description: price.String(),
+			Caption:     messageText,
+			ParseMode:   "HTML",
+		})
+	}
+
+	return results
+}
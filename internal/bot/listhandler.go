@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// listHandler handles the /list [page] command. It lists the price agents of the chat with their current price,
+// split into pages of priceagentsPerPage price agents to stay below the message size limit.
+func listHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	priceagents, dbErr := database.GetPriceagentsForChat(ctx.EffectiveChat.Id)
+	if dbErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten! Bitte probiere es später erneut!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("listHandler: %w", dbErr)
+	}
+
+	if len(priceagents) == 0 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Du hast noch keine Preisagenten angelegt! Nutze /add <url>, um einen neuen Preisagenten anzulegen.", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	// pages are numbered from 1 for the user
+	page := 1
+	if args := ctx.Args(); len(args) > 1 {
+		if parsedPage, parseErr := strconv.Atoi(args[1]); parseErr == nil {
+			page = parsedPage
+		}
+	}
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, listText(priceagents, page-1), &gotgbot.SendMessageOpts{
+		ParseMode:          "HTML",
+		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true},
+	})
+	if replyErr != nil {
+		return fmt.Errorf("listHandler: failed to send reply: %w", replyErr)
+	}
+
+	return nil
+}
+
+// listText generates the text of /list for the given page of the price agents. Pages are counted from 0.
+func listText(priceagents []models.PriceAgent, page int) string {
+	pagePriceagents, page, totalPages := paginatePriceagents(priceagents, page, priceagentsPerPage)
+
+	lines := make([]string, 0, len(pagePriceagents))
+	for _, priceagent := range pagePriceagents {
+		price := priceagent.CurrentEntityPrice()
+
+		settings := priceagent.NotificationSettings.String()
+		if !priceagent.Enabled {
+			settings = "pausiert"
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s - %s (%s)", bold(fmt.Sprintf("#%d", priceagent.ID)), createLink(priceagent.EntityURL(), priceagent.Name), bold(price.String()), settings))
+	}
+
+	title := "Deine Preisagenten"
+	if totalPages > 1 {
+		title = fmt.Sprintf("Deine Preisagenten (Seite %d/%d)", page+1, totalPages)
+	}
+
+	text := fmt.Sprintf("%s\n\n%s\n\n", bold(title), strings.Join(lines, "\n"))
+	if page+1 < totalPages {
+		text += fmt.Sprintf("Mit /list %d siehst du die nächste Seite.\n", page+2)
+	}
+
+	return text + "Mit /remove &lt;id&gt; kannst du einen Preisagenten löschen."
+}
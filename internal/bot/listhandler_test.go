@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_listText(t *testing.T) {
+	priceagents := make([]models.PriceAgent, 25)
+	for i := range priceagents {
+		priceagents[i] = models.PriceAgent{
+			ID:       int64(i + 1),
+			Name:     fmt.Sprintf("Produkt %d", i+1),
+			Enabled:  true,
+			Location: "de",
+			Entity:   geizhals.Entity{ID: int64(i + 1), URL: fmt.Sprintf("produkt-a%d.html", i+1), Type: geizhals.Product},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		priceagents  []models.PriceAgent
+		page         int
+		wantLines    int
+		wantContains []string
+		wantMissing  []string
+	}{
+		{
+			name:         "Single page",
+			priceagents:  priceagents[:3],
+			wantLines:    3,
+			wantContains: []string{"<b>Deine Preisagenten</b>", "<b>#3</b>"},
+			wantMissing:  []string{"Seite", "/list"},
+		},
+		{
+			name:         "First page",
+			priceagents:  priceagents,
+			wantLines:    priceagentsPerPage,
+			wantContains: []string{"(Seite 1/3)", "<b>#10</b>", "Mit /list 2 siehst du die nächste Seite."},
+			wantMissing:  []string{"<b>#11</b>"},
+		},
+		{
+			name:         "Last page",
+			priceagents:  priceagents,
+			page:         2,
+			wantLines:    5,
+			wantContains: []string{"(Seite 3/3)", "<b>#25</b>"},
+			wantMissing:  []string{"/list"},
+		},
+		{
+			name:         "Page out of range",
+			priceagents:  priceagents,
+			page:         10,
+			wantLines:    5,
+			wantContains: []string{"(Seite 3/3)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listText(tt.priceagents, tt.page)
+
+			if lines := strings.Count(got, "</b> <a href="); lines != tt.wantLines {
+				t.Errorf("listText() lists %d price agents, want %d", lines, tt.wantLines)
+			}
+
+			for _, want := range tt.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("listText() = %q, want it to contain %q", got, want)
+				}
+			}
+
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(got, missing) {
+					t.Errorf("listText() = %q, don't want it to contain %q", got, missing)
+				}
+			}
+		})
+	}
+}
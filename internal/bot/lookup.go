@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// normalizeProductCode removes whitespace and dashes which are often used to group the digits of EANs.
+// Manufacturer part numbers are returned as they are, apart from surrounding whitespace.
+func normalizeProductCode(code string) string {
+	code = strings.TrimSpace(code)
+
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(code)
+	if digits != "" && strings.Trim(digits, "0123456789") == "" {
+		return digits
+	}
+
+	return code
+}
+
+// isGTINCandidate returns true if the given code only consists of digits and has the length of a GTIN.
+// Numeric codes of other lengths are treated as manufacturer part numbers.
+func isGTINCandidate(code string) bool {
+	return geizhals.HasGTINLength(code) && strings.Trim(code, "0123456789") == ""
+}
+
+// productCodesText returns a line with the EAN and MPN of the given entity or an empty string if both are unknown.
+func productCodesText(entity geizhals.Entity) string {
+	var codes []string
+
+	if entity.EAN != "" {
+		codes = append(codes, fmt.Sprintf("EAN: <code>%s</code>", html.EscapeString(entity.EAN)))
+	}
+
+	if entity.MPN != "" {
+		codes = append(codes, fmt.Sprintf("MPN: <code>%s</code>", html.EscapeString(entity.MPN)))
+	}
+
+	if len(codes) == 0 {
+		return ""
+	}
+
+	return "\n" + strings.Join(codes, " · ")
+}
+
+// lookupProductCode resolves an EAN or a manufacturer part number to Geizhals products via the search
+// and replies with the results.
+func lookupProductCode(bot *gotgbot.Bot, ctx *ext.Context, text string) error {
+	query, location := parseSearchQuery(text)
+	code := normalizeProductCode(query)
+
+	if isGTINCandidate(code) && !geizhals.IsValidGTIN(code) {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Die Prüfziffer der EAN ist ungültig. Bitte überprüfe die Nummer!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	return sendSearchResults(bot, ctx, code, location)
+}
+
+// lookupHandler handles the /lookup command: /lookup [location] <EAN|MPN>
+func lookupHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	args := ctx.Args()
+	if len(args) < 2 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /lookup <EAN|Herstellernummer>", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	return lookupProductCode(bot, ctx, strings.Join(args[1:], " "))
+}
@@ -0,0 +1,48 @@
+package bot
+
+import "testing"
+
+func Test_normalizeProductCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "EAN", code: "4006381333931", want: "4006381333931"},
+		{name: "Grouped EAN", code: " 4 006381 333931 ", want: "4006381333931"},
+		{name: "EAN with dashes", code: "400-6381-333931", want: "4006381333931"},
+		{name: "MPN", code: " 100-100000910WOF ", want: "100-100000910WOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeProductCode(tt.code); got != tt.want {
+				t.Errorf("normalizeProductCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isGTINCandidate(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "EAN-13", code: "4006381333931", want: true},
+		{name: "EAN-8", code: "96385074", want: true},
+		{name: "UPC", code: "036000291452", want: true},
+		{name: "GTIN-14", code: "14006381333938", want: true},
+		{name: "Numeric MPN with 9 digits", code: "123456789", want: false},
+		{name: "Numeric MPN with 11 digits", code: "12345678901", want: false},
+		{name: "Too short", code: "1234567", want: false},
+		{name: "Too long", code: "123456789012345", want: false},
+		{name: "Alphanumeric MPN", code: "100-100000910WOF", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGTINCandidate(tt.code); got != tt.want {
+				t.Errorf("isGTINCandidate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
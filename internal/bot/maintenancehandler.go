@@ -3,15 +3,126 @@ package bot
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
 )
 
-// maintenanceHandler
+// maintenanceState holds the reasons for which the bot is in maintenance mode. While in maintenance mode, users
+// only get the maintenance message instead of the menus.
+type maintenanceState struct {
+	mu sync.RWMutex
+	// manual is set by the config or by an admin
+	manual bool
+	// automatic is set while the scraper health check fails
+	automatic bool
+}
+
+var maintenance maintenanceState
+
+// setManual enables or disables the maintenance mode set by the config or by an admin.
+// Disabling it also ends the automatic maintenance mode, e.g. to override a false alarm of the health check.
+func (m *maintenanceState) setManual(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.manual = enabled
+	if !enabled {
+		m.automatic = false
+	}
+}
+
+// setAutomatic enables or disables the maintenance mode caused by a failing scraper health check.
+func (m *maintenanceState) setAutomatic(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.automatic = enabled
+}
+
+// active checks if the bot is in maintenance mode for any reason.
+func (m *maintenanceState) active() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.manual || m.automatic
+}
+
+// status returns whether the maintenance mode is enabled manually and automatically.
+func (m *maintenanceState) status() (manual, automatic bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.manual, m.automatic
+}
+
+// maintenanceText generates the message shown to users while the bot is in maintenance mode.
+// The automatic maintenance mode is explained by a likely change of the Geizhals website.
+func maintenanceText(automatic bool) string {
+	reason := "Der Bot wird gerade gewartet und steht in Kürze wieder zur Verfügung."
+	if automatic {
+		reason = "Geizhals hat vermutlich seine Webseite umgestaltet, wodurch der Bot aktuell keine Daten auslesen kann. Sobald das Problem behoben ist, steht der Bot wieder zur Verfügung."
+	}
+
+	return fmt.Sprintf("🛠️ Hallo lieber Nutzer und vielen Dank für dein Interesse an diesem Bot.\n\n%s Deine Preisagenten bleiben erhalten.\n\nWenn du programmieren kannst, schau dir gerne den %s an - vielleicht hast du gerade etwas Zeit zum unterstützen?\n\nVielen Dank für dein Verständnis!",
+		reason, createLink("https://github.com/d-Rickyy-b/GoGeizhalsBot", "Quellcode auf GitHub"))
+}
+
+// inMaintenance checks if an update of the given user has to be answered with the maintenance message.
+// Admins can use the bot during maintenance, e.g. to disable the maintenance mode again.
+func inMaintenance(user *gotgbot.User) bool {
+	if !maintenance.active() {
+		return false
+	}
+
+	return user == nil || !isAdmin(user.Id)
+}
+
+// maintenanceAction describes how a message is handled while the bot is in maintenance mode.
+type maintenanceAction int
+
+const (
+	// maintenanceNone processes the message as usual
+	maintenanceNone maintenanceAction = iota
+	// maintenanceReply answers the message with the maintenance message
+	maintenanceReply
+	// maintenanceIgnore drops the message without an answer
+	maintenanceIgnore
+)
+
+// messageMaintenanceAction decides how a message is handled. While in maintenance mode, private messages and commands
+// of users are answered with the maintenance message. All other messages in groups are ignored to not spam the group.
+// Messages of admins are processed as usual.
+func messageMaintenanceAction(msg *gotgbot.Message, active, admin bool) maintenanceAction {
+	if !active || admin {
+		return maintenanceNone
+	}
+
+	if msg.Chat.Type == gotgbot.ChatTypePrivate || message.Command(msg) {
+		return maintenanceReply
+	}
+
+	return maintenanceIgnore
+}
+
+// maintenanceMessageFilter matches the messages which are handled with the given action in the current maintenance state.
+func maintenanceMessageFilter(action maintenanceAction) filters.Message {
+	return func(msg *gotgbot.Message) bool {
+		active := maintenance.active()
+		admin := active && msg.From != nil && isAdmin(msg.From.Id)
+
+		return messageMaintenanceAction(msg, active, admin) == action
+	}
+}
+
+// maintenanceHandler answers messages with the maintenance message and stops the processing of the update.
 func maintenanceHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
-	maintenanceText := fmt.Sprintf("Hallo lieber Nutzer und vielen Dank für dein Interesse an diesem Bot.\n\nVor einiger Zeit wurde die Geizhals Webseite umgestaltet, wodurch der Bot keine Daten mehr auslesen kann. Aktuell schaffe ich es zeitlich nicht, den Bot an die neue Seite anzupassen. Diese Anpassung kommt mit erheblichem Aufwand daher, weshalb ich den Bot erst einmal deaktiviert habe.\n\nWenn du programmieren kannst, schau dir gerne den %s an - vielleicht hast du gerade etwas Zeit zum unterstützen?\n\nVielen Dank für dein Verständnis!", createLink("https://github.com/d-Rickyy-b/GoGeizhalsBot", "Quellcode auf GitHub"))
-	_, replyErr := ctx.EffectiveMessage.Reply(bot, maintenanceText, &gotgbot.SendMessageOpts{
+	_, automatic := maintenance.status()
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, maintenanceText(automatic), &gotgbot.SendMessageOpts{
 		ParseMode: "HTML",
 		LinkPreviewOptions: &gotgbot.LinkPreviewOptions{
 			IsDisabled: true,
@@ -22,5 +133,36 @@ func maintenanceHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		log.Println(replyErr)
 	}
 
-	return nil
+	return ext.EndGroups
+}
+
+// maintenanceIgnoreHandler drops messages in groups while the bot is in maintenance mode.
+func maintenanceIgnoreHandler(_ *gotgbot.Bot, _ *ext.Context) error {
+	return ext.EndGroups
+}
+
+// maintenanceCallbackHandler answers callback queries with an alert while the bot is in maintenance mode.
+func maintenanceCallbackHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	_, answerErr := ctx.CallbackQuery.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "🛠️ Der Bot wird gerade gewartet. Bitte versuche es später erneut!", ShowAlert: true})
+	if answerErr != nil {
+		log.Println(answerErr)
+	}
+
+	return ext.EndGroups
+}
+
+// maintenanceInlineQueryHandler answers inline queries without results while the bot is in maintenance mode.
+func maintenanceInlineQueryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cacheTime := int64(60)
+
+	_, answerErr := ctx.InlineQuery.Answer(bot, []gotgbot.InlineQueryResult{}, &gotgbot.AnswerInlineQueryOpts{
+		CacheTime:  &cacheTime,
+		IsPersonal: true,
+		Button:     &gotgbot.InlineQueryResultsButton{Text: "🛠️ Der Bot wird gerade gewartet", StartParameter: "maintenance"},
+	})
+	if answerErr != nil {
+		log.Println(answerErr)
+	}
+
+	return ext.EndGroups
 }
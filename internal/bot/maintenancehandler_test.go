@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+func Test_maintenanceState(t *testing.T) {
+	tests := []struct {
+		name          string
+		apply         func(m *maintenanceState)
+		wantActive    bool
+		wantManual    bool
+		wantAutomatic bool
+	}{
+		{
+			name:  "Inactive by default",
+			apply: func(m *maintenanceState) {},
+		},
+		{
+			name:          "Automatic",
+			apply:         func(m *maintenanceState) { m.setAutomatic(true) },
+			wantActive:    true,
+			wantAutomatic: true,
+		},
+		{
+			name: "Automatic recovered",
+			apply: func(m *maintenanceState) {
+				m.setManual(true)
+				m.setAutomatic(true)
+				m.setAutomatic(false)
+			},
+			wantActive: true,
+			wantManual: true,
+		},
+		{
+			name: "Admin overrides automatic",
+			apply: func(m *maintenanceState) {
+				m.setAutomatic(true)
+				m.setManual(false)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m maintenanceState
+			tt.apply(&m)
+
+			manual, automatic := m.status()
+			if m.active() != tt.wantActive || manual != tt.wantManual || automatic != tt.wantAutomatic {
+				t.Errorf("maintenanceState = (active %v, manual %v, automatic %v), want (%v, %v, %v)", m.active(), manual, automatic, tt.wantActive, tt.wantManual, tt.wantAutomatic)
+			}
+		})
+	}
+}
+
+func Test_messageMaintenanceAction(t *testing.T) {
+	privateChat := gotgbot.Chat{Id: 123, Type: gotgbot.ChatTypePrivate}
+	groupChat := gotgbot.Chat{Id: -100, Type: gotgbot.ChatTypeSupergroup}
+	command := []gotgbot.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}}
+
+	tests := []struct {
+		name   string
+		msg    gotgbot.Message
+		active bool
+		admin  bool
+		want   maintenanceAction
+	}{
+		{
+			name: "Not in maintenance",
+			msg:  gotgbot.Message{Chat: groupChat, Text: "https://geizhals.de/a123.html"},
+			want: maintenanceNone,
+		},
+		{
+			name:   "Private message",
+			msg:    gotgbot.Message{Chat: privateChat, Text: "Hallo"},
+			active: true,
+			want:   maintenanceReply,
+		},
+		{
+			name:   "Command in group",
+			msg:    gotgbot.Message{Chat: groupChat, Text: "/list", Entities: command},
+			active: true,
+			want:   maintenanceReply,
+		},
+		{
+			name:   "Link in group",
+			msg:    gotgbot.Message{Chat: groupChat, Text: "https://geizhals.de/a123.html"},
+			active: true,
+			want:   maintenanceIgnore,
+		},
+		{
+			name:   "Admin in group",
+			msg:    gotgbot.Message{Chat: groupChat, Text: "https://geizhals.de/a123.html"},
+			active: true,
+			admin:  true,
+			want:   maintenanceNone,
+		},
+		{
+			name:   "Admin in private chat",
+			msg:    gotgbot.Message{Chat: privateChat, Text: "/admin", Entities: command},
+			active: true,
+			admin:  true,
+			want:   maintenanceNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageMaintenanceAction(&tt.msg, tt.active, tt.admin); got != tt.want {
+				t.Errorf("messageMaintenanceAction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
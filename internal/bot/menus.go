@@ -1,5 +1,7 @@
 package bot
 
+import "github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+
 const (
 	MainMenuState = "m00_00"
 
@@ -8,27 +10,75 @@ const (
 
 	ShowWishlistPriceagentsState = "m02_00"
 	ShowProductPriceagentsState  = "m02_01"
+	FilterPriceagentsState       = "m02_02"
+	ClearPriceagentsFilterState  = "m02_03"
+	ShowCategoryPriceagentsState = "m02_04"
 
 	ShowPriceagentDetailState = "m03_00"
 
 	ChangePriceagentSettingsState = "m04_00"
 	SetNotificationAlwaysState    = "m04_01"
 	SetNotificationBelowState     = "m04_02"
+	RenamePriceagentState         = "m04_03"
+	PausePriceagentState          = "m04_04"
+	ResumePriceagentState         = "m04_05"
 	DeletePriceagentConfirmState  = "m04_98"
 	DeletePriceagentState         = "m04_99"
 
-	ShowPriceHistoryState     = "m05_00"
-	UpdateHistoryGraph1State  = "m05_01"
-	UpdateHistoryGraph3State  = "m05_03"
-	UpdateHistoryGraph6State  = "m05_06"
-	UpdateHistoryGraph12State = "m05_12"
+	ShowPriceHistoryState   = "m05_00"
+	UpdateHistoryGraphState = "m05_01"
 
 	StopConfirmState = "m06_01"
 	StopCancelState  = "m06_02"
+
+	PreviewCreatePriceagentState = "m07_00"
+	PreviewPriceHistoryState     = "m07_01"
+
+	PriceagentTagsState      = "m08_00"
+	AddPriceagentTagState    = "m08_01"
+	AssignPriceagentTagState = "m08_02"
+	RemovePriceagentTagState = "m08_03"
+
+	ShowTagsState             = "m09_00"
+	ShowTagPriceagentsState   = "m09_01"
+	PauseTagPriceagentsState  = "m09_02"
+	ResumeTagPriceagentsState = "m09_03"
+
+	ShowWishlistItemsState            = "m10_00"
+	CreateWishlistItemPriceagentState = "m10_01"
+
+	SearchProductState          = "m11_00"
+	SearchCreatePriceagentState = "m11_01"
+
+	ShowVariantsState         = "m12_00"
+	TrackCheapestVariantState = "m12_01"
+
+	CompareLocationsState      = "m13_00"
+	ToggleCompareLocationState = "m13_01"
+
+	CleanupPriceagentsState        = "m14_00"
+	CleanupPriceagentsConfirmState = "m14_01"
+
+	ConfirmBroadcastState = "m15_00"
+	CancelBroadcastState  = "m15_01"
+)
+
+// Fields of the callback data in addition to callback.FieldID, which holds the ID of the price agent, entity or tag of a menu
+const (
+	FieldLocation = callback.FieldLocation
+	FieldPage     = callback.FieldPage
+	FieldSort     = callback.FieldSort
+	FieldList     = callback.FieldList
+	FieldTagID    = callback.FieldTagID
+	FieldRange    = callback.FieldRange
+	FieldDarkMode = callback.FieldDarkMode
+	FieldDisplay  = "v"
+	FieldEntity   = "e"
 )
 
+// Display modes of the price agent detail menu
 const (
-	Menu0 = "00"
-	Menu1 = "01"
-	Menu2 = "02"
+	Menu0 = "00" // edit the current message
+	Menu1 = "01" // replace the current message, e.g. a photo, with a new message
+	Menu2 = "02" // send a new message
 )
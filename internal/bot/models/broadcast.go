@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// BroadcastStatus is the state of a broadcast
+type BroadcastStatus int
+
+const (
+	// BroadcastDraft is a broadcast whose preview was sent to the admin, but which wasn't confirmed yet
+	BroadcastDraft BroadcastStatus = 0
+	// BroadcastRunning is a broadcast which is being sent, it's resumed after a restart
+	BroadcastRunning  BroadcastStatus = 1
+	BroadcastDone     BroadcastStatus = 2
+	BroadcastCanceled BroadcastStatus = 3
+)
+
+// Broadcast is an announcement of an admin which is sent to all users. The progress is stored after every user,
+// so that an interrupted broadcast can be resumed after a restart.
+type Broadcast struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ID        int64           `json:"id" gorm:"primarykey;autoIncrement:true"`
+	AdminID   int64           `json:"admin_id"`
+	Text      string          `json:"text"`
+	Status    BroadcastStatus `json:"status" gorm:"not null;default:0"`
+	// LastUserID is the ID of the last user the broadcast was processed for, users are processed in ascending order
+	LastUserID int64 `json:"last_user_id"`
+	Sent       int64 `json:"sent"`
+	Blocked    int64 `json:"blocked"`
+	Failed     int64 `json:"failed"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNewMenu(t *testing.T) {
+	tests := []struct {
+		name     string
+		menuData string
+		want     Menu
+		wantErr  bool
+	}{
+		{"menu with id", "m03_00_123", Menu{ID: "m03", SubMenu: "00", PriceAgentID: 123}, false},
+		{"menu with extra", "m05_03_5_1", Menu{ID: "m05", SubMenu: "03", PriceAgentID: 5, Extra: "1"}, false},
+		{"menu without id", "m00_00", Menu{}, true},
+		{"invalid id", "m03_00_abc", Menu{}, true},
+		{"too many components", "m03_00_1_2_3", Menu{}, true},
+		{"too long", "m03_00_1_" + strings.Repeat("x", 64), Menu{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMenu(tt.menuData)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMenu() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && *got != tt.want {
+				t.Errorf("NewMenu() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzNewMenu(f *testing.F) {
+	for _, seed := range []string{"m03_00_123", "m05_03_5_1", "m00_00", "___", "m03_00_-1_x", "m03_00_99999999999999999999"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, menuData string) {
+		menu, err := NewMenu(menuData)
+		if err != nil {
+			return
+		}
+
+		if len(menuData) > 64 {
+			t.Fatalf("NewMenu(%q) accepted more than 64 bytes", menuData)
+		}
+
+		// A parsed menu must be made up of the components of the menu data
+		rebuilt := menu.ID + "_" + menu.SubMenu + "_" + strconv.FormatInt(menu.PriceAgentID, 10)
+		if strings.Count(menuData, "_") == 3 {
+			rebuilt += "_" + menu.Extra
+		}
+
+		if reparsed, reparseErr := NewMenu(rebuilt); reparseErr != nil || *reparsed != *menu {
+			t.Fatalf("NewMenu(%q) = %+v, reparsing %q = %+v, %v", menuData, *menu, rebuilt, reparsed, reparseErr)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
@@ -11,18 +12,28 @@ type PriceAgent struct {
 	CreatedAt            time.Time
 	ID                   int64                `json:"id" gorm:"primarykey;autoIncrement:true"`
 	Name                 string               `json:"name"`
-	UserID               int64                `json:"user_id" gorm:"not null;default:null;index:user_entity_idx,unique"`
+	UserID               int64                `json:"user_id" gorm:"not null;default:null"`
 	User                 User                 `json:"user" gorm:"foreignkey:UserID"`
-	EntityID             int64                `json:"-" gorm:"index:user_entity_idx,unique"`
+	ChatID               int64                `json:"chat_id" gorm:"index:chat_entity_idx,unique"`
+	EntityID             int64                `json:"-" gorm:"index:chat_entity_idx,unique"`
 	Entity               geizhals.Entity      `json:"entity" gorm:"foreignkey:EntityID"`
 	Location             string               `json:"location" gorm:"default:de"`
 	NotificationID       int64                `json:"-"`
 	NotificationSettings NotificationSettings `json:"notificationSettings" gorm:"foreignkey:NotificationID;constraint:OnDelete:CASCADE;"`
 	Enabled              bool                 `json:"enabled" gorm:"default:1"`
+	Tags                 []Tag                `json:"tags" gorm:"many2many:price_agent_tags;"`
+	// CompareLocations is a comma separated list of further locations, whose prices are compared with the price of Location
+	CompareLocations string `json:"compareLocations" gorm:"default:''"`
 }
 
 func (pa PriceAgent) String() string {
-	return fmt.Sprintf("%d - '%s' (%s) | User: %d", pa.ID, pa.Name, pa.Entity.Name, pa.UserID)
+	return fmt.Sprintf("%d - '%s' (%s) | User: %d | Chat: %d", pa.ID, pa.Name, pa.Entity.Name, pa.UserID, pa.ChatID)
+}
+
+// IsGroupPriceagent returns true if the price agent is shared within a group chat instead of belonging to a single user.
+// For private chats the chat ID equals the user ID.
+func (pa PriceAgent) IsGroupPriceagent() bool {
+	return pa.ChatID != pa.UserID
 }
 
 func (pa PriceAgent) EntityURL() string {
@@ -37,6 +48,15 @@ func (pa PriceAgent) CurrentPrice() float64 {
 	return pa.Entity.GetPrice(pa.Location).Price
 }
 
+// GetCompareLocations returns the locations whose prices are compared with the price of the price agent's location.
+func (pa PriceAgent) GetCompareLocations() []string {
+	if pa.CompareLocations == "" {
+		return nil
+	}
+
+	return strings.Split(pa.CompareLocations, ",")
+}
+
 func (pa PriceAgent) GetCurrency() geizhals.Currency {
 	return pa.Entity.GetPrice(pa.Location).Currency
 }
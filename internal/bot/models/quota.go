@@ -0,0 +1,20 @@
+package models
+
+// DefaultTierName is the name of the tier which applies to all chats without a tier
+const DefaultTierName = "default"
+
+// Tier is a named limit of price agents, e.g. "default" with 5 or "team" with 100 price agents.
+type Tier struct {
+	ID             int64  `json:"id" gorm:"primarykey;autoIncrement:true"`
+	Name           string `json:"name" gorm:"unique;not null"`
+	MaxPriceAgents int64  `json:"max_price_agents"`
+}
+
+// ChatQuota assigns a tier or an individual limit of price agents to a chat. For private chats the chat ID is the user ID.
+type ChatQuota struct {
+	ChatID int64  `json:"chat_id" gorm:"primaryKey;autoIncrement:false"`
+	TierID *int64 `json:"tier_id"`
+	Tier   *Tier  `json:"tier" gorm:"foreignkey:TierID"`
+	// MaxPriceAgents overrides the limit of the tier if set
+	MaxPriceAgents *int64 `json:"max_price_agents"`
+}
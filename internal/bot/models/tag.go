@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Tag is a user defined label which groups the price agents of a chat, e.g. "PC-Build" or "Haushalt".
+type Tag struct {
+	CreatedAt   time.Time
+	ID          int64        `json:"id" gorm:"primarykey;autoIncrement:true"`
+	ChatID      int64        `json:"chat_id" gorm:"index:chat_tag_idx,unique"`
+	Name        string       `json:"name" gorm:"index:chat_tag_idx,unique"`
+	PriceAgents []PriceAgent `json:"-" gorm:"many2many:price_agent_tags;"`
+}
+
+func (t Tag) String() string {
+	return t.Name
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+var (
+	ErrMaxPriceagentsReached = errors.New("maximum number of price agents reached")
+	ErrPriceagentExists      = errors.New("price agent for entity already exists")
+)
+
+// createPriceagent creates a new price agent for the given entity in the given chat. The user is stored as the
+// creator of the price agent. It makes sure that the chat does not exceed its limit of price agents and
+// doesn't already watch the entity.
+func createPriceagent(userID, chatID int64, entity geizhals.Entity, location string) (models.PriceAgent, error) {
+	limit, _, limitErr := priceagentLimit(chatID)
+	if limitErr != nil {
+		return models.PriceAgent{}, fmt.Errorf("createPriceagent: %w", limitErr)
+	}
+
+	if database.GetPriceAgentCountForChat(chatID) >= limit {
+		return models.PriceAgent{}, ErrMaxPriceagentsReached
+	}
+
+	hasPriceAgent, checkErr := database.HasChatPriceAgentForEntity(chatID, entity.ID)
+	if checkErr != nil {
+		return models.PriceAgent{}, fmt.Errorf("createPriceagent: %w", checkErr)
+	}
+
+	if hasPriceAgent {
+		return models.PriceAgent{}, ErrPriceagentExists
+	}
+
+	newPriceagent := models.PriceAgent{
+		Name:   entity.Name,
+		UserID: userID,
+		ChatID: chatID,
+		Entity: entity,
+		NotificationSettings: models.NotificationSettings{
+			NotifyAlways: true,
+		},
+		Location: location,
+	}
+
+	createErr := database.CreatePriceAgentForChat(&newPriceagent)
+	if createErr != nil {
+		return models.PriceAgent{}, fmt.Errorf("createPriceagent: %w", createErr)
+	}
+
+	return newPriceagent, nil
+}
+
+// createPriceagentFromButton downloads the entity of the given URL and creates a price agent for it in the current chat.
+// It answers the callback query of the pressed button and sends a message with a link to the new price agent.
+func createPriceagentFromButton(bot *gotgbot.Bot, ctx *ext.Context, entityURL, location string) error {
+	cbq := ctx.Update.CallbackQuery
+
+	entity, downloadErr := geizhals.DownloadEntity(entityURL)
+	if downloadErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Das Produkt konnte nicht geladen werden!", ShowAlert: true})
+		return fmt.Errorf("createPriceagentFromButton: %w", downloadErr)
+	}
+
+	newPriceagent, createErr := createPriceagent(ctx.EffectiveUser.Id, ctx.EffectiveChat.Id, entity, location)
+	if createErr != nil {
+		var answerText string
+
+		switch {
+		case errors.Is(createErr, ErrPriceagentExists):
+			answerText = "Du hast bereits einen Preisagenten für dieses Produkt!"
+		case errors.Is(createErr, ErrMaxPriceagentsReached):
+			answerText = "Du hast bereits die maximale Anzahl an Preisagenten angelegt!"
+		default:
+			log.Printf("createPriceagentFromButton: %s\n", createErr)
+			answerText = "Es ist ein Fehler aufgetreten!"
+		}
+
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: answerText, ShowAlert: true})
+
+		return nil
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Preisagent wurde erstellt!"}); err != nil {
+		return fmt.Errorf("createPriceagentFromButton: failed to answer callback query: %w", err)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, newPriceagent.ID)},
+		},
+	}}
+
+	text := fmt.Sprintf("Preisagent für %s wurde erstellt!", createLink(newPriceagent.EntityURL(), newPriceagent.Name))
+
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if sendErr != nil {
+		return fmt.Errorf("createPriceagentFromButton: failed to send message: %w", sendErr)
+	}
+
+	return nil
+}
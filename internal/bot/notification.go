@@ -1,8 +1,14 @@
 package bot
 
 import (
+	"cmp"
 	"fmt"
+	"html"
 	"log"
+	"math"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
@@ -14,7 +20,8 @@ import (
 )
 
 type tempPriceStore struct {
-	store map[int64]map[string]float64
+	store       map[int64]map[string]float64
+	itemChanges map[int64]map[string][]geizhals.WishlistItemChange
 }
 
 func (t *tempPriceStore) getPrice(entityID int64, location string) (float64, bool) {
@@ -45,6 +52,44 @@ func (t *tempPriceStore) storePrice(entityID int64, location string, price float
 	t.store[entityID] = entity
 }
 
+func (t *tempPriceStore) getItemChanges(entityID int64, location string) []geizhals.WishlistItemChange {
+	return t.itemChanges[entityID][location]
+}
+
+func (t *tempPriceStore) storeItemChanges(entityID int64, location string, changes []geizhals.WishlistItemChange) {
+	if t.itemChanges == nil {
+		t.itemChanges = make(map[int64]map[string][]geizhals.WishlistItemChange)
+	}
+
+	entity, entityOk := t.itemChanges[entityID]
+	if !entityOk {
+		entity = make(map[string][]geizhals.WishlistItemChange)
+	}
+
+	entity[location] = changes
+	t.itemChanges[entityID] = entity
+}
+
+// updateWishlistItems stores the current items of a wishlist and returns the changes of the items since the last check.
+// Wishlists without stored items, e.g. before their first check, don't report any changes.
+func updateWishlistItems(entityID int64, location string, items []geizhals.WishlistItem) []geizhals.WishlistItemChange {
+	oldItems, fetchErr := database.GetWishlistItems(entityID, location)
+	if fetchErr != nil {
+		log.Println("Error fetching wishlist items:", fetchErr)
+		return nil
+	}
+
+	if replaceErr := database.ReplaceWishlistItems(entityID, location, items); replaceErr != nil {
+		log.Println("Error storing wishlist items:", replaceErr)
+	}
+
+	if len(oldItems) == 0 {
+		return nil
+	}
+
+	return geizhals.DiffWishlistItems(oldItems, items)
+}
+
 // updateEntityPrices fetches the current price of all entities and updates the database
 func updateEntityPrices() {
 	allPriceAgents, fetchErr := database.GetActivePriceAgents()
@@ -62,70 +107,304 @@ func updateEntityPrices() {
 
 		price, isCached = priceStore.getPrice(priceAgent.EntityID, priceAgent.Location)
 		if !isCached {
-			updatedPrice, updateErr := geizhals.UpdateEntityPrice(priceAgent.Entity, priceAgent.Location)
-			if updateErr != nil {
-				log.Println("Error updating entity:", updateErr)
+			updatedEntity, updateErr := geizhals.UpdateEntity(priceAgent.Entity, priceAgent.Location)
+			if updateErr != nil || len(updatedEntity.Prices) == 0 {
+				status := geizhals.StatusFromError(updateErr)
+				if status == geizhals.StatusActive {
+					status = geizhals.StatusError
+				}
+
+				if status == geizhals.StatusError {
+					log.Println("Error updating entity:", updateErr)
+				}
+
+				updateEntityStatus(priceAgent, status, 0)
+
 				continue
 			}
+			updatedPrice := updatedEntity.Prices[0]
 
-			if updatedPrice.Price == priceAgent.CurrentPrice() {
-				log.Println("Entity price has not changed, skipping update")
-				continue
+			// Geizhals renames the slugs of products from time to time, the canonical path is kept up to date
+			if updatedEntity.URL != "" && updatedEntity.URL != priceAgent.Entity.URL {
+				log.Printf("Migrating URL of entity %d from '%s' to '%s'\n", priceAgent.EntityID, priceAgent.Entity.URL, updatedEntity.URL)
+				if urlErr := database.UpdateEntityURL(priceAgent.EntityID, updatedEntity.URL); urlErr != nil {
+					log.Println("Error updating entity URL:", urlErr)
+				}
+			}
+
+			switch updatedEntity.Type {
+			case geizhals.Wishlist:
+				priceStore.storeItemChanges(priceAgent.EntityID, priceAgent.Location, updateWishlistItems(priceAgent.EntityID, priceAgent.Location, updatedEntity.Items))
+			case geizhals.Category:
+				priceStore.storeItemChanges(priceAgent.EntityID, priceAgent.Location, newCategoryProducts(updateWishlistItems(priceAgent.EntityID, priceAgent.Location, updatedEntity.Items)))
+			case geizhals.ProductFamily:
+				priceStore.storeItemChanges(priceAgent.EntityID, priceAgent.Location, cheapestVariantChanges(updateWishlistItems(priceAgent.EntityID, priceAgent.Location, updatedEntity.Items), updatedPrice.Price))
+			}
+
+			if updatedPrice.Price != priceAgent.CurrentPrice() {
+				database.UpdateEntityPrice(updatedPrice)
 			}
 
-			database.UpdateEntityPrice(updatedPrice)
 			price = updatedPrice.Price
+			priceStore.storePrice(priceAgent.EntityID, priceAgent.Location, price)
+		}
+
+		updateComparePrices(priceAgent, price, &priceStore)
+
+		if updateEntityStatus(priceAgent, geizhals.StatusActive, price) {
+			// The notification about the reappearance already contains the current price
+			continue
 		}
 
-		notifyUsers(priceAgent, priceAgent.CurrentPrice(), price)
+		itemChanges := priceStore.getItemChanges(priceAgent.EntityID, priceAgent.Location)
+
+		// Changes of the contents of a wishlist or new products in a category are reported even if the price stays the same
+		if price == priceAgent.CurrentPrice() && !geizhals.HasCompositionChange(itemChanges) {
+			log.Println("Entity price has not changed, skipping update")
+			continue
+		}
+
+		notifyUsers(priceAgent, priceAgent.CurrentPrice(), price, itemChanges)
 	}
 }
 
-// notifyUsers sends a notification to the users of the price agent if the settings allow it
-func notifyUsers(priceAgent models.PriceAgent, oldPrice, updatedPrice float64) {
+// notifyUsers sends a notification to the users of the price agent if the settings allow it.
+// For wishlists, the items which were added, removed or whose price changed are listed as the cause of the change.
+func notifyUsers(priceAgent models.PriceAgent, oldPrice, updatedPrice float64, itemChanges []geizhals.WishlistItemChange) {
 	settings := priceAgent.NotificationSettings
-	diff := updatedPrice - oldPrice
 
-	var change string
-	if updatedPrice > oldPrice {
-		change = fmt.Sprintf("📈 %s teurer", bold(createPrice(diff, priceAgent.GetCurrency().String())))
-	} else {
-		change = fmt.Sprintf("📉 %s günstiger", bold(createPrice(diff, priceAgent.GetCurrency().String())))
-	}
-
-	var notificationText string
-	entityLink := createLink(priceAgent.EntityURL(), priceAgent.Entity.Name)
-	entityPrice := bold(createPrice(updatedPrice, priceAgent.GetCurrency().String()))
-
-	switch {
-	case settings.NotifyAlways:
-		notificationText = fmt.Sprintf("Der Preis von %s hat sich geändert: %s\n\n%s", entityLink, entityPrice, change)
-	case settings.NotifyBelow && updatedPrice < settings.BelowPrice:
-		notificationText = fmt.Sprintf("Der Preis von %s hat sich geändert: %s\n\n%s", entityLink, entityPrice, change)
-	default:
+	if !settings.NotifyAlways && (!settings.NotifyBelow || updatedPrice >= settings.BelowPrice) {
 		log.Println("Price changes don't match the notification settings for user")
 		return
 	}
 
-	log.Println("Sending notification to user:", priceAgent.UserID)
-	prometheus.PriceagentNotifications.Inc()
+	sendNotification(priceAgent, notificationMessage(priceAgent, oldPrice, updatedPrice, itemChanges))
+}
 
+// sendNotification sends the given notification text to the chat of the price agent along with a button to the price agent.
+func sendNotification(priceAgent models.PriceAgent, notificationText string) {
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: "Zum Preisagenten!", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, priceAgent.ID)},
+				{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceAgent.ID)},
 			},
 		},
 	}
+
+	sendNotificationWithMarkup(priceAgent, notificationText, markup)
+}
+
+// sendNotificationWithMarkup sends the given notification text with the given keyboard to the chat of the price agent.
+func sendNotificationWithMarkup(priceAgent models.PriceAgent, notificationText string, markup gotgbot.InlineKeyboardMarkup) {
+	log.Println("Sending notification to chat:", priceAgent.ChatID)
+	prometheus.PriceagentNotifications.Inc()
+
 	// TODO implement message queueing to avoid hitting telegram api limits (30 msgs/sec)
 
 	sendMessageOpts := &gotgbot.SendMessageOpts{ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}, ReplyMarkup: markup}
-	_, sendErr := bot.SendMessage(priceAgent.UserID, notificationText, sendMessageOpts)
+	_, sendErr := bot.SendMessage(priceAgent.ChatID, notificationText, sendMessageOpts)
 	if sendErr != nil {
 		log.Println("Error sending message:", sendErr)
 	}
 }
 
+// newCategoryProducts returns the products which entered the cheapest products of a category. Products which
+// dropped out of them or changed their price are not reported, the price of the category is its cheapest product.
+func newCategoryProducts(itemChanges []geizhals.WishlistItemChange) []geizhals.WishlistItemChange {
+	var added []geizhals.WishlistItemChange
+
+	for _, itemChange := range itemChanges {
+		if itemChange.Type == geizhals.ItemAdded {
+			added = append(added, itemChange)
+		}
+	}
+
+	return added
+}
+
+// cheapestVariantChanges returns the change of the member of a product family which is the cheapest after the update.
+// Changes of the other members don't affect the price of the family and are not reported.
+func cheapestVariantChanges(itemChanges []geizhals.WishlistItemChange, cheapestPrice float64) []geizhals.WishlistItemChange {
+	for _, itemChange := range itemChanges {
+		if itemChange.Type != geizhals.ItemRemoved && itemChange.Item.Price == cheapestPrice {
+			return []geizhals.WishlistItemChange{itemChange}
+		}
+	}
+
+	return nil
+}
+
+// notificationMessage generates the text of the notification about a changed price. Changes of the contents of
+// a wishlist are phrased differently from price changes, as the total changed because of the owner of the wishlist.
+func notificationMessage(priceAgent models.PriceAgent, oldPrice, updatedPrice float64, itemChanges []geizhals.WishlistItemChange) string {
+	if priceAgent.Entity.Type == geizhals.Category {
+		return categoryNotificationMessage(priceAgent, oldPrice, updatedPrice, itemChanges)
+	}
+
+	if priceAgent.Entity.Type == geizhals.ProductFamily {
+		return familyNotificationMessage(priceAgent, oldPrice, updatedPrice, itemChanges)
+	}
+
+	currency := priceAgent.GetCurrency().String()
+	diff := updatedPrice - oldPrice
+
+	var change string
+	if updatedPrice > oldPrice {
+		change = fmt.Sprintf("📈 %s teurer", bold(createPrice(diff, currency)))
+	} else {
+		change = fmt.Sprintf("📉 %s günstiger", bold(createPrice(diff, currency)))
+	}
+
+	entityLink := createLink(priceAgent.EntityURL(), priceAgent.Entity.Name)
+	entityPrice := bold(createPrice(updatedPrice, currency))
+
+	var compositionChanges, priceChanges []geizhals.WishlistItemChange
+
+	for _, itemChange := range itemChanges {
+		if itemChange.IsCompositionChange() {
+			compositionChanges = append(compositionChanges, itemChange)
+		} else {
+			priceChanges = append(priceChanges, itemChange)
+		}
+	}
+
+	var notificationText string
+
+	if len(compositionChanges) > 0 {
+		notificationText = fmt.Sprintf("Die Wunschliste %s wurde verändert:\n%s\n\nSumme: %s (%s)", entityLink, wishlistItemChangesText(compositionChanges, currency), entityPrice, signedPrice(diff, currency))
+		if len(priceChanges) > 0 {
+			notificationText += fmt.Sprintf("\n\nAußerdem haben sich Preise geändert:\n%s", wishlistItemChangesText(priceChanges, currency))
+		}
+
+		return notificationText
+	}
+
+	notificationText = fmt.Sprintf("Der Preis von %s hat sich geändert: %s\n\n%s", entityLink, entityPrice, change)
+	if len(priceChanges) > 0 {
+		notificationText += fmt.Sprintf("\n\nVerursacht durch:\n%s", wishlistItemChangesText(priceChanges, currency))
+	}
+
+	return notificationText
+}
+
+// categoryNotificationMessage generates the text of the notification about new products among the cheapest
+// products of a category or a changed price of the cheapest product.
+func categoryNotificationMessage(priceAgent models.PriceAgent, oldPrice, updatedPrice float64, newProducts []geizhals.WishlistItemChange) string {
+	currency := priceAgent.GetCurrency().String()
+	entityLink := createLink(priceAgent.EntityURL(), priceAgent.Entity.Name)
+	entityPrice := bold(createPrice(updatedPrice, currency))
+
+	if len(newProducts) == 0 {
+		return fmt.Sprintf("Der günstigste Preis in %s hat sich geändert: %s (%s)", entityLink, entityPrice, signedPrice(updatedPrice-oldPrice, currency))
+	}
+
+	lines := make([]string, 0, len(newProducts))
+	for _, product := range newProducts {
+		lines = append(lines, fmt.Sprintf("• %s: %s", createLink(product.Item.FullURL(priceAgent.Location), product.Item.Name), createPrice(product.Item.Price, currency)))
+	}
+
+	notificationText := fmt.Sprintf("Neu unter den %d günstigsten Produkten in %s:\n%s\n\nGünstigster Preis: %s", geizhals.CategoryTopN, entityLink, strings.Join(lines, "\n"), entityPrice)
+	if updatedPrice != oldPrice {
+		notificationText += fmt.Sprintf(" (%s)", signedPrice(updatedPrice-oldPrice, currency))
+	}
+
+	return notificationText
+}
+
+// familyNotificationMessage generates the text of the notification about a changed price of the cheapest member
+// of a product family. The member is named if it is known which one is the cheapest.
+func familyNotificationMessage(priceAgent models.PriceAgent, oldPrice, updatedPrice float64, cheapestChanges []geizhals.WishlistItemChange) string {
+	currency := priceAgent.GetCurrency().String()
+	entityLink := createLink(priceAgent.EntityURL(), priceAgent.Entity.Name)
+	entityPrice := bold(createPrice(updatedPrice, currency))
+
+	if len(cheapestChanges) == 0 {
+		return fmt.Sprintf("Der Preis der günstigsten Variante von %s hat sich geändert: %s (%s)", entityLink, entityPrice, signedPrice(updatedPrice-oldPrice, currency))
+	}
+
+	cheapest := cheapestChanges[0].Item
+
+	return fmt.Sprintf("Die günstigste Variante von %s ist jetzt %s für %s (%s)", entityLink, createLink(cheapest.FullURL(priceAgent.Location), cheapest.Name), entityPrice, signedPrice(updatedPrice-oldPrice, currency))
+}
+
+// signedPrice formats a price difference with a leading sign
+func signedPrice(diff float64, currency string) string {
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+	}
+
+	return sign + createPrice(math.Abs(diff), currency)
+}
+
+// maxWishlistChangesShown is the maximum number of changed items listed per section of a notification to stay below
+// the message size limit
+const maxWishlistChangesShown = 10
+
+// wishlistItemChangesText lists the changed items of a wishlist, sorted by the impact on the total.
+// Only the maxWishlistChangesShown items with the highest impact are listed, the others are counted.
+func wishlistItemChangesText(itemChanges []geizhals.WishlistItemChange, currency string) string {
+	changes := slices.Clone(itemChanges)
+	slices.SortStableFunc(changes, func(a, b geizhals.WishlistItemChange) int {
+		return cmp.Compare(math.Abs(b.Difference()), math.Abs(a.Difference()))
+	})
+
+	lines := make([]string, 0, min(len(changes), maxWishlistChangesShown+1))
+
+	for i, change := range changes {
+		if i >= maxWishlistChangesShown {
+			lines = append(lines, fmt.Sprintf("… und %d weitere Artikel", len(changes)-maxWishlistChangesShown))
+			break
+		}
+
+		name := html.EscapeString(change.Item.Name)
+		if name == "" {
+			name = fmt.Sprintf("Artikel %d", change.Item.EntityID)
+		}
+
+		var line string
+
+		switch change.Type {
+		case geizhals.ItemAdded:
+			line = fmt.Sprintf("• %d× %s hinzugefügt", change.Item.Amount, name)
+		case geizhals.ItemRemoved:
+			line = fmt.Sprintf("• %d× %s entfernt", change.Item.Amount, name)
+		case geizhals.ItemAmountChanged:
+			line = fmt.Sprintf("• %s: Anzahl %d → %d", name, change.OldAmount, change.Item.Amount)
+		default:
+			line = fmt.Sprintf("• %s: %s → %s", name, createPrice(change.OldPrice, currency), createPrice(change.Item.Price, currency))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s (%s)", line, signedPrice(change.Difference(), currency)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// priceUpdateMu makes sure that only a single price update runs at a time, e.g. when an admin triggers an update
+// while the scheduled update is still running.
+var priceUpdateMu sync.Mutex
+
+// runPriceUpdate updates the prices of all price agents and checks the health of the scraper afterwards.
+// It returns false without updating if another update is already running.
+func runPriceUpdate() bool {
+	if !priceUpdateMu.TryLock() {
+		return false
+	}
+	defer priceUpdateMu.Unlock()
+
+	updatePrices()
+
+	return true
+}
+
+// updatePrices updates the prices of all price agents and checks the health of the scraper afterwards.
+// The caller must hold priceUpdateMu.
+func updatePrices() {
+	updateEntityPrices()
+	checkScraperHealth()
+}
+
 // UpdatePricesJob is a job that updates prices of all price agents at a given interval.
 func UpdatePricesJob(updateFrequency time.Duration) {
 	// Align method execution at certain intervals - e.g. every 5 minutes at :05, :10, :15, etc. similar to cron.
@@ -134,7 +413,9 @@ func UpdatePricesJob(updateFrequency time.Duration) {
 		log.Println("Sleeping for:", sleepDuration)
 		time.Sleep(sleepDuration)
 
-		updateEntityPrices()
+		if !runPriceUpdate() {
+			log.Println("Price update is still running, skipping scheduled update")
+		}
 	}
 }
 
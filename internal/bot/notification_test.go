@@ -1,6 +1,12 @@
 package bot
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
 
 func Test_tempPriceStore_storePrice(t1 *testing.T) {
 	t := tempPriceStore{}
@@ -35,3 +41,164 @@ func Test_tempPriceStore_storePrice(t1 *testing.T) {
 		t1.Errorf("Price shouldn't exist for other location!")
 	}
 }
+
+func Test_wishlistItemChangesText(t *testing.T) {
+	changes := []geizhals.WishlistItemChange{
+		{Type: geizhals.ItemPriceChanged, Item: geizhals.WishlistItem{EntityID: 1, Name: "Maus", Amount: 1, Price: 21}, OldPrice: 20, OldAmount: 1},
+		{Type: geizhals.ItemPriceChanged, Item: geizhals.WishlistItem{EntityID: 2, Name: "RAM <32GB>", Amount: 2, Price: 40}, OldPrice: 50, OldAmount: 2},
+		{Type: geizhals.ItemAdded, Item: geizhals.WishlistItem{EntityID: 3, Amount: 1, Price: 5}},
+		{Type: geizhals.ItemRemoved, Item: geizhals.WishlistItem{EntityID: 4, Name: "Kabel", Amount: 2, Price: 3}, OldPrice: 3, OldAmount: 2},
+		{Type: geizhals.ItemAmountChanged, Item: geizhals.WishlistItem{EntityID: 5, Name: "SSD", Amount: 2, Price: 50}, OldPrice: 50, OldAmount: 1},
+	}
+
+	want := "• SSD: Anzahl 1 → 2 (+50.00 €)" +
+		"\n• RAM &lt;32GB&gt;: 50.00 € → 40.00 € (-20.00 €)" +
+		"\n• 2× Kabel entfernt (-6.00 €)" +
+		"\n• 1× Artikel 3 hinzugefügt (+5.00 €)" +
+		"\n• Maus: 20.00 € → 21.00 € (+1.00 €)"
+
+	if got := wishlistItemChangesText(changes, "€"); got != want {
+		t.Errorf("wishlistItemChangesText() = %q, want %q", got, want)
+	}
+
+	var manyChanges []geizhals.WishlistItemChange
+	for i := range maxWishlistChangesShown + 5 {
+		manyChanges = append(manyChanges, geizhals.WishlistItemChange{Type: geizhals.ItemAdded, Item: geizhals.WishlistItem{EntityID: int64(i), Name: "Artikel", Amount: 1, Price: float64(i)}})
+	}
+
+	got := wishlistItemChangesText(manyChanges, "€")
+	if lines := strings.Split(got, "\n"); len(lines) != maxWishlistChangesShown+1 || lines[len(lines)-1] != "… und 5 weitere Artikel" {
+		t.Errorf("wishlistItemChangesText() = %q, want %d items and a line with the remaining items", got, maxWishlistChangesShown)
+	}
+}
+
+func Test_notificationMessage(t *testing.T) {
+	priceAgent := models.PriceAgent{
+		Location: "de",
+		Entity:   geizhals.Entity{ID: -1, Name: "PC", URL: "?cat=WL-1", Type: geizhals.Wishlist},
+	}
+	added := geizhals.WishlistItemChange{Type: geizhals.ItemAdded, Item: geizhals.WishlistItem{EntityID: 3, Name: "GPU", Amount: 1, Price: 49}}
+	priceChange := geizhals.WishlistItemChange{Type: geizhals.ItemPriceChanged, Item: geizhals.WishlistItem{EntityID: 1, Name: "Maus", Amount: 1, Price: 21}, OldPrice: 20, OldAmount: 1}
+	link := `<a href="https://geizhals.de/?cat=WL-1">PC</a>`
+
+	tests := []struct {
+		name        string
+		oldPrice    float64
+		newPrice    float64
+		itemChanges []geizhals.WishlistItemChange
+		want        string
+	}{
+		{
+			name:     "Price change",
+			oldPrice: 100,
+			newPrice: 101,
+			want:     "Der Preis von " + link + " hat sich geändert: <b>101.00 €</b>\n\n📈 <b>1.00 €</b> teurer",
+		},
+		{
+			name:        "Price change of an item",
+			oldPrice:    100,
+			newPrice:    101,
+			itemChanges: []geizhals.WishlistItemChange{priceChange},
+			want:        "Der Preis von " + link + " hat sich geändert: <b>101.00 €</b>\n\n📈 <b>1.00 €</b> teurer\n\nVerursacht durch:\n• Maus: 20.00 € → 21.00 € (+1.00 €)",
+		},
+		{
+			name:        "Added item",
+			oldPrice:    100,
+			newPrice:    149,
+			itemChanges: []geizhals.WishlistItemChange{added},
+			want:        "Die Wunschliste " + link + " wurde verändert:\n• 1× GPU hinzugefügt (+49.00 €)\n\nSumme: <b>149.00 €</b> (+49.00 €)",
+		},
+		{
+			name:        "Added item and price change",
+			oldPrice:    100,
+			newPrice:    150,
+			itemChanges: []geizhals.WishlistItemChange{priceChange, added},
+			want:        "Die Wunschliste " + link + " wurde verändert:\n• 1× GPU hinzugefügt (+49.00 €)\n\nSumme: <b>150.00 €</b> (+50.00 €)\n\nAußerdem haben sich Preise geändert:\n• Maus: 20.00 € → 21.00 € (+1.00 €)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notificationMessage(priceAgent, tt.oldPrice, tt.newPrice, tt.itemChanges); got != tt.want {
+				t.Errorf("notificationMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_categoryNotificationMessage(t *testing.T) {
+	priceAgent := models.PriceAgent{
+		Location: "de",
+		Entity:   geizhals.Entity{ID: 1 << 62, Name: "Grafikkarten", URL: "?cat=gra16_512&sort=p", Type: geizhals.Category},
+	}
+	link := `<a href="https://geizhals.de/?cat=gra16_512&amp;sort=p">Grafikkarten</a>`
+	added := geizhals.WishlistItemChange{Type: geizhals.ItemAdded, Item: geizhals.WishlistItem{EntityID: 3, Name: "GPU", URL: "gpu-a3.html", Amount: 1, Price: 449}}
+
+	tests := []struct {
+		name        string
+		oldPrice    float64
+		newPrice    float64
+		newProducts []geizhals.WishlistItemChange
+		want        string
+	}{
+		{
+			name:     "Cheapest price changed",
+			oldPrice: 499,
+			newPrice: 479,
+			want:     "Der günstigste Preis in " + link + " hat sich geändert: <b>479.00 €</b> (-20.00 €)",
+		},
+		{
+			name:        "New cheapest product",
+			oldPrice:    499,
+			newPrice:    449,
+			newProducts: []geizhals.WishlistItemChange{added},
+			want:        "Neu unter den 5 günstigsten Produkten in " + link + ":\n• <a href=\"https://geizhals.de/gpu-a3.html\">GPU</a>: 449.00 €\n\nGünstigster Preis: <b>449.00 €</b> (-50.00 €)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notificationMessage(priceAgent, tt.oldPrice, tt.newPrice, tt.newProducts); got != tt.want {
+				t.Errorf("notificationMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_familyNotificationMessage(t *testing.T) {
+	priceAgent := models.PriceAgent{
+		Location: "de",
+		Entity:   geizhals.Entity{ID: 1 << 62, Name: "Apple iPhone 15", URL: "?cmp=1&cmp=2", Type: geizhals.ProductFamily},
+	}
+	link := `<a href="https://geizhals.de/?cmp=1&amp;cmp=2">Apple iPhone 15</a>`
+	changed := geizhals.WishlistItemChange{Type: geizhals.ItemPriceChanged, Item: geizhals.WishlistItem{EntityID: 2, Name: "Apple iPhone 15 256GB blau", URL: "iphone-a2.html", Amount: 1, Price: 749}, OldPrice: 829, OldAmount: 1}
+	removed := geizhals.WishlistItemChange{Type: geizhals.ItemRemoved, Item: geizhals.WishlistItem{EntityID: 1, Name: "Apple iPhone 15 128GB schwarz", Amount: 1, Price: 749}}
+
+	tests := []struct {
+		name        string
+		oldPrice    float64
+		newPrice    float64
+		itemChanges []geizhals.WishlistItemChange
+		want        string
+	}{
+		{
+			name:        "Cheapest variant known",
+			oldPrice:    799,
+			newPrice:    749,
+			itemChanges: cheapestVariantChanges([]geizhals.WishlistItemChange{changed}, 749),
+			want:        "Die günstigste Variante von " + link + " ist jetzt <a href=\"https://geizhals.de/iphone-a2.html\">Apple iPhone 15 256GB blau</a> für <b>749.00 €</b> (-50.00 €)",
+		},
+		{
+			name:        "Cheapest variant removed",
+			oldPrice:    749,
+			newPrice:    799,
+			itemChanges: cheapestVariantChanges([]geizhals.WishlistItemChange{removed}, 799),
+			want:        "Der Preis der günstigsten Variante von " + link + " hat sich geändert: <b>799.00 €</b> (+50.00 €)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notificationMessage(priceAgent, tt.oldPrice, tt.newPrice, tt.itemChanges); got != tt.want {
+				t.Errorf("notificationMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
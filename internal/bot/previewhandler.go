@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// textEntityPreviewHandler handles text messages sent outside any menu. If the message contains a link to a
+// Geizhals product or wishlist, a preview with the current price is sent to the user.
+func textEntityPreviewHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	urls := extractEntityURLs(ctx.EffectiveMessage)
+	if len(urls) == 0 {
+		return nil
+	}
+
+	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "typing", nil)
+
+	entityURL := urls[0]
+
+	entity, downloadErr := geizhals.DownloadEntity(entityURL)
+	if downloadErr != nil {
+		log.Printf("textEntityPreviewHandler: %s\n", downloadErr)
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Problem beim Abrufen der Daten aufgetreten! Bitte versuche es später erneut", &gotgbot.SendMessageOpts{})
+
+		return nil
+	}
+
+	location, parseErr := geizhals.LocationFromURL(entityURL)
+	if parseErr != nil {
+		log.Printf("textEntityPreviewHandler: %s\n", parseErr)
+		return nil
+	}
+
+	// The entity must be stored so that the buttons of the preview can refer to it
+	if saveErr := database.SaveEntity(entity); saveErr != nil {
+		return fmt.Errorf("textEntityPreviewHandler: failed to save entity: %w", saveErr)
+	}
+
+	linkName := createLink(entity.FullURL(location), entity.Name)
+	price := entity.GetPrice(location)
+	text := fmt.Sprintf("%s kostet aktuell %s", linkName, bold(price.String()))
+	text += productCodesText(entity)
+	markup := gotgbot.InlineKeyboardMarkup{
+		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+			{
+				{Text: "🆕 Preisagent anlegen", CallbackData: entityCallback(PreviewCreatePriceagentState, entity.ID, location)},
+				{Text: "📊 Preisverlauf", CallbackData: entityCallback(PreviewPriceHistoryState, entity.ID, location)},
+			},
+		},
+	}
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if replyErr != nil {
+		return fmt.Errorf("textEntityPreviewHandler: failed to send preview: %w", replyErr)
+	}
+
+	return nil
+}
+
+// previewCreatePriceagentHandler handles the "create price agent" button below an entity preview.
+func previewCreatePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	entity, location, parseErr := parseMenuEntity(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("previewCreatePriceagentHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	priceagent, createErr := createPriceagent(ctx.EffectiveUser.Id, ctx.EffectiveChat.Id, entity, location)
+	if createErr != nil {
+		var answerText string
+
+		switch {
+		case errors.Is(createErr, ErrPriceagentExists):
+			answerText = "Du hast bereits einen Preisagenten für dieses Produkt!"
+		case errors.Is(createErr, ErrMaxPriceagentsReached):
+			answerText = "Du hast bereits die maximale Anzahl an Preisagenten angelegt!"
+		default:
+			answerText = "Es ist ein Fehler aufgetreten!"
+		}
+
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: answerText, ShowAlert: true})
+
+		return nil
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Preisagent wurde erstellt!"}); err != nil {
+		return fmt.Errorf("previewCreatePriceagentHandler: failed to answer callback query: %w", err)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+		},
+	}}
+
+	_, _, editErr := cbq.Message.EditReplyMarkup(bot, &gotgbot.EditMessageReplyMarkupOpts{ReplyMarkup: markup})
+	if editErr != nil {
+		return fmt.Errorf("previewCreatePriceagentHandler: failed to edit reply markup: %w", editErr)
+	}
+
+	return nil
+}
+
+// previewPriceHistoryHandler handles the "price history" button below an entity preview.
+// It sends a price history chart for the last 3 months without requiring a price agent.
+func previewPriceHistoryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	entity, location, parseErr := parseMenuEntity(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("previewPriceHistoryHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "upload_photo", nil)
+
+	history, historyErr := geizhals.GetPriceHistory(entity, location)
+	if historyErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Preisverlaufdaten konnten nicht geladen werden."})
+		return fmt.Errorf("previewPriceHistoryHandler: failed to download pricehistory: %w", historyErr)
+	}
+
+	if len(history.Response) == 0 {
+		log.Println("previewPriceHistoryHandler: pricehistory is empty")
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Preisverlaufdaten konnten nicht geladen werden."})
+
+		return nil
+	}
+
+	_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{})
+
+	// Charts are rendered for price agents, so we use a temporary one which is never stored
+	priceagent := models.PriceAgent{Name: entity.Name, Entity: entity, Location: location}
+	isDarkmode := database.GetDarkmode(ctx.EffectiveUser.Id)
+
+	buffer := bytes.NewBuffer([]byte{})
+	renderChart(priceagent, history, time.Now().AddDate(0, -3, 0), buffer, isDarkmode)
+
+	caption := fmt.Sprintf("%s\nPreisverlauf der letzten 3 Monate", bold(createLink(entity.FullURL(location), entity.Name)))
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "🆕 Preisagent anlegen", CallbackData: entityCallback(PreviewCreatePriceagentState, entity.ID, location)},
+		},
+	}}
+
+	inputFile := gotgbot.InputFileByReader("chart.png", buffer)
+	chartMessage, sendErr := bot.SendPhoto(ctx.EffectiveChat.Id, inputFile, &gotgbot.SendPhotoOpts{Caption: caption, ReplyMarkup: markup, ParseMode: "HTML"})
+	if sendErr != nil {
+		return fmt.Errorf("previewPriceHistoryHandler: failed to send photo: %w", sendErr)
+	}
+
+	storeChartFromMessage(chartKey{entityID: entity.ID, location: location, dateRange: "03", darkMode: isDarkmode}, chartMessage)
+
+	return nil
+}
+
+// entityCallback returns the encoded callback data for a menu of an entity at the given location
+func entityCallback(action string, entityID int64, location string) string {
+	return callback.New(action).With(callback.FieldID, entityID).With(FieldLocation, location).MustEncode()
+}
+
+// parseMenuEntity parses the callback data of an entity preview button and loads the referenced entity.
+// The ID field of the callback data holds the entity ID, the location is stored in a separate field.
+func parseMenuEntity(ctx *ext.Context) (geizhals.Entity, string, error) {
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return geizhals.Entity{}, "", newStaleButtonError(StaleInvalidData, decodeErr)
+	}
+
+	location := data.Get(FieldLocation)
+	if !isAllowedLocation(location) {
+		return geizhals.Entity{}, "", newStaleButtonError(StaleInvalidData, fmt.Errorf("invalid location in callback data: %s", ctx.CallbackQuery.Data))
+	}
+
+	entityID, idErr := data.Int(callback.FieldID)
+	if idErr != nil {
+		return geizhals.Entity{}, "", newStaleButtonError(StaleInvalidData, idErr)
+	}
+
+	entity, dbErr := database.GetEntityByID(entityID)
+	if dbErr != nil {
+		return geizhals.Entity{}, "", newStaleButtonError(StaleEntityNotFound, dbErr)
+	}
+
+	return entity, location, nil
+}
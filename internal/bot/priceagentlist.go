@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// priceagentsPerPage is the number of price agents displayed on a single page of the price agent lists
+const priceagentsPerPage = 10
+
+// Sort orders for the price agent lists. They are part of the callback data, hence they are kept short.
+const (
+	SortByName    = "n"
+	SortByPrice   = "p"
+	SortByChange  = "c"
+	SortByCreated = "d"
+)
+
+// listKeys maps the short keys used in callback data to the price agent list states
+var listKeys = map[string]string{
+	"w": ShowWishlistPriceagentsState,
+	"p": ShowProductPriceagentsState,
+	"c": ShowCategoryPriceagentsState,
+}
+
+// sortOrders defines the order in which the sort button cycles through the sort orders
+var sortOrders = []string{SortByName, SortByPrice, SortByChange, SortByCreated}
+
+var sortOrderNames = map[string]string{
+	SortByName:    "Name",
+	SortByPrice:   "Preis",
+	SortByChange:  "Letzte Änderung",
+	SortByCreated: "Erstellt",
+}
+
+// priceagentListOptions defines which part of a price agent list is displayed.
+type priceagentListOptions struct {
+	Page      int
+	SortOrder string
+	Filter    string
+}
+
+// nextSortOrder returns the sort order following the given one.
+func nextSortOrder(sortOrder string) string {
+	for i, order := range sortOrders {
+		if order == sortOrder {
+			return sortOrders[(i+1)%len(sortOrders)]
+		}
+	}
+
+	return SortByName
+}
+
+// sortPriceagents sorts the given price agents in place. Names and prices are sorted in ascending order,
+// last change and creation date are sorted with the newest price agents first.
+func sortPriceagents(priceagents []models.PriceAgent, sortOrder string) {
+	sort.SliceStable(priceagents, func(i, j int) bool {
+		a, b := priceagents[i], priceagents[j]
+
+		switch sortOrder {
+		case SortByPrice:
+			return a.CurrentPrice() < b.CurrentPrice()
+		case SortByChange:
+			return a.CurrentEntityPrice().UpdatedAt.After(b.CurrentEntityPrice().UpdatedAt)
+		case SortByCreated:
+			return a.CreatedAt.After(b.CreatedAt)
+		default:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+	})
+}
+
+// filterPriceagents returns the price agents whose name contains the filter text, ignoring case.
+func filterPriceagents(priceagents []models.PriceAgent, filter string) []models.PriceAgent {
+	if filter == "" {
+		return priceagents
+	}
+
+	filter = strings.ToLower(filter)
+
+	var filtered []models.PriceAgent
+
+	for _, priceagent := range priceagents {
+		if strings.Contains(strings.ToLower(priceagent.Name), filter) {
+			filtered = append(filtered, priceagent)
+		}
+	}
+
+	return filtered
+}
+
+// paginatePriceagents returns the price agents of the given page along with the corrected page number
+// and the total number of pages. Out of range page numbers are clamped to the first or last page.
+func paginatePriceagents(priceagents []models.PriceAgent, page, pageSize int) ([]models.PriceAgent, int, int) {
+	totalPages := (len(priceagents) + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		return priceagents, 0, 1
+	}
+
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+
+	if page < 0 {
+		page = 0
+	}
+
+	start := page * pageSize
+	end := min(start+pageSize, len(priceagents))
+
+	return priceagents[start:end], page, totalPages
+}
+
+// parseListOptions parses the page and sort order from the callback data of a price agent list.
+// Missing or invalid fields fall back to the first page sorted by name.
+func parseListOptions(data callback.Data) priceagentListOptions {
+	options := priceagentListOptions{Page: 0, SortOrder: SortByName}
+
+	if page, pageErr := data.Int(FieldPage); pageErr == nil {
+		options.Page = int(page)
+	}
+
+	if _, ok := sortOrderNames[data.Get(FieldSort)]; ok {
+		options.SortOrder = data.Get(FieldSort)
+	}
+
+	return options
+}
+
+// listFilterKey returns the key of the filter of the user for the given price agent list in the current chat.
+func listFilterKey(ctx *ext.Context, listState string) userstate.ListFilterKey {
+	return userstate.ListFilterKey{UserID: ctx.EffectiveUser.Id, ChatID: ctx.EffectiveChat.Id, List: listState}
+}
+
+// listCallbackData generates the callback data for a specific page of a price agent list.
+func listCallbackData(listState string, page int, sortOrder string) string {
+	return callback.New(listState).With(FieldPage, page).With(FieldSort, sortOrder).MustEncode()
+}
+
+// priceagentListMessage generates the text and keyboard for the wishlist or product price agent list of a chat.
+func priceagentListMessage(chatID int64, listState string, options priceagentListOptions) (string, gotgbot.InlineKeyboardMarkup) {
+	var (
+		priceagents []models.PriceAgent
+		emptyText   string
+		listText    string
+	)
+
+	switch listState {
+	case ShowWishlistPriceagentsState:
+		priceagents, _ = database.GetWishlistPriceagentsForChat(chatID)
+		emptyText = "Du hast noch keine Preisagenten für Wunschlisten angelegt!"
+		listText = "Das sind deine Preisagenten für deine Wunschlisten:"
+	case ShowCategoryPriceagentsState:
+		priceagents, _ = database.GetCategoryPriceagentsForChat(chatID)
+		emptyText = "Du hast noch keine Preisagenten für Kategorien angelegt! Sende mir dafür die URL einer Kategorie mit deinen Filtern."
+		listText = "Das sind deine Preisagenten für deine Kategorien:"
+	default:
+		priceagents, _ = database.GetProductPriceagentsForChat(chatID)
+		emptyText = "Du hast noch keine Preisagenten für Produkte angelegt!"
+		listText = "Das sind deine Preisagenten für deine Produkte:"
+	}
+
+	if len(priceagents) == 0 {
+		return emptyText, generateEntityKeyboard(priceagents, callback.New(ShowPriceagentDetailState), 2)
+	}
+
+	priceagents = filterPriceagents(priceagents, options.Filter)
+	sortPriceagents(priceagents, options.SortOrder)
+	pagePriceagents, page, totalPages := paginatePriceagents(priceagents, options.Page, priceagentsPerPage)
+
+	messageText := fmt.Sprintf("%s\n\nSortierung: %s", listText, sortOrderNames[options.SortOrder])
+	if options.Filter != "" {
+		messageText += fmt.Sprintf("\nFilter: '%s' (%d Treffer)", options.Filter, len(priceagents))
+	}
+
+	if totalPages > 1 {
+		messageText += fmt.Sprintf("\nSeite %d von %d", page+1, totalPages)
+	}
+
+	// The detail menus remember the page and sort order for their back button
+	detailData := callback.New(ShowPriceagentDetailState).With(FieldPage, page).With(FieldSort, options.SortOrder)
+	markup := generateEntityKeyboard(pagePriceagents, detailData, 2)
+
+	var navigationRow []gotgbot.InlineKeyboardButton
+	if page > 0 {
+		navigationRow = append(navigationRow, gotgbot.InlineKeyboardButton{Text: "⬅️", CallbackData: listCallbackData(listState, page-1, options.SortOrder)})
+	}
+
+	navigationRow = append(navigationRow, gotgbot.InlineKeyboardButton{
+		Text:         fmt.Sprintf("↕️ %s", sortOrderNames[nextSortOrder(options.SortOrder)]),
+		CallbackData: listCallbackData(listState, 0, nextSortOrder(options.SortOrder)),
+	})
+
+	var listKey string
+
+	for key, state := range listKeys {
+		if state == listState {
+			listKey = key
+		}
+	}
+
+	if options.Filter == "" {
+		navigationRow = append(navigationRow, gotgbot.InlineKeyboardButton{Text: "🔍 Filter", CallbackData: callback.New(FilterPriceagentsState).With(FieldList, listKey).MustEncode()})
+	} else {
+		navigationRow = append(navigationRow, gotgbot.InlineKeyboardButton{Text: "❌ Filter", CallbackData: callback.New(ClearPriceagentsFilterState).With(FieldList, listKey).MustEncode()})
+	}
+
+	if page < totalPages-1 {
+		navigationRow = append(navigationRow, gotgbot.InlineKeyboardButton{Text: "➡️", CallbackData: listCallbackData(listState, page+1, options.SortOrder)})
+	}
+
+	// Navigation goes right above the bottom row containing the new/back buttons
+	keyboard := markup.InlineKeyboard
+	bottomRow := keyboard[len(keyboard)-1]
+	keyboard = append(keyboard[:len(keyboard)-1], navigationRow, bottomRow)
+
+	return messageText, gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// showPriceagentList displays a page of the wishlist or product price agent list.
+func showPriceagentList(bot *gotgbot.Bot, ctx *ext.Context, listState string) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return fmt.Errorf("showPriceagentList: failed to parse callback data: %w", newStaleButtonError(StaleInvalidData, decodeErr))
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showPriceagentList: failed to answer callback query: %w", err)
+	}
+
+	options := parseListOptions(data)
+	options.Filter = userstate.ListFilters[listFilterKey(ctx, listState)]
+
+	messageText, markup := priceagentListMessage(ctx.EffectiveChat.Id, listState, options)
+
+	_, _, err := cbq.Message.EditText(bot, messageText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
+	if err != nil {
+		return fmt.Errorf("showPriceagentList: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// filterPriceagentsHandler handles the filter button of the price agent lists. It asks the user for a filter text.
+func filterPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return fmt.Errorf("filterPriceagentsHandler: failed to parse callback data: %w", newStaleButtonError(StaleInvalidData, decodeErr))
+	}
+
+	listState, ok := listKeys[data.Get(FieldList)]
+	if !ok {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("filterPriceagentsHandler: invalid list in callback data: %s", cbq.Data))
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("filterPriceagentsHandler: failed to answer callback query: %w", err)
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.FilterPriceagents, ChatID: ctx.EffectiveChat.Id, ListMenu: listState}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "↩️ Zurück", CallbackData: menuCallback(listState)},
+		},
+	}}
+
+	_, _, err := cbq.Message.EditText(bot, "Bitte sende mir den Text, nach dem die Preisagenten gefiltert werden sollen!", &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
+	if err != nil {
+		return fmt.Errorf("filterPriceagentsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// clearPriceagentsFilterHandler handles the button to remove the filter of the price agent lists.
+func clearPriceagentsFilterHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return fmt.Errorf("clearPriceagentsFilterHandler: failed to parse callback data: %w", newStaleButtonError(StaleInvalidData, decodeErr))
+	}
+
+	listState, ok := listKeys[data.Get(FieldList)]
+	if !ok {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("clearPriceagentsFilterHandler: invalid list in callback data: %s", ctx.Update.CallbackQuery.Data))
+	}
+
+	delete(userstate.ListFilters, listFilterKey(ctx, listState))
+
+	return showPriceagentList(bot, ctx, listState)
+}
+
+// textFilterPriceagentsHandler handles the text message containing the filter for the price agent lists.
+func textFilterPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	state := ctx.Data["state"].(userstate.UserState)
+	filter := strings.TrimSpace(ctx.EffectiveMessage.Text)
+
+	userstate.ListFilters[listFilterKey(ctx, state.ListMenu)] = filter
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.Idle, ChatID: ctx.EffectiveChat.Id}
+
+	options := priceagentListOptions{Page: 0, SortOrder: SortByName, Filter: filter}
+	messageText, markup := priceagentListMessage(ctx.EffectiveChat.Id, state.ListMenu, options)
+
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, messageText, &gotgbot.SendMessageOpts{ReplyMarkup: markup})
+	if sendErr != nil {
+		return fmt.Errorf("textFilterPriceagentsHandler: failed to send message: %w", sendErr)
+	}
+
+	return nil
+}
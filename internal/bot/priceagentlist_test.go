@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_paginatePriceagents(t *testing.T) {
+	priceagents := make([]models.PriceAgent, 25)
+
+	tests := []struct {
+		name           string
+		page           int
+		wantLen        int
+		wantPage       int
+		wantTotalPages int
+	}{
+		{"first page", 0, 10, 0, 3},
+		{"last page", 2, 5, 2, 3},
+		{"page out of range", 7, 5, 2, 3},
+		{"negative page", -1, 10, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page, totalPages := paginatePriceagents(priceagents, tt.page, 10)
+			if len(got) != tt.wantLen || page != tt.wantPage || totalPages != tt.wantTotalPages {
+				t.Errorf("paginatePriceagents() = %d, %d, %d, want %d, %d, %d", len(got), page, totalPages, tt.wantLen, tt.wantPage, tt.wantTotalPages)
+			}
+		})
+	}
+}
+
+func Test_parseListOptions(t *testing.T) {
+	options := parseListOptions(callback.New(ShowProductPriceagentsState))
+	if options.Page != 0 || options.SortOrder != SortByName {
+		t.Errorf("parseListOptions() = %+v, want first page sorted by name", options)
+	}
+
+	data, decodeErr := callback.Decode(listCallbackData(ShowWishlistPriceagentsState, 3, SortByPrice))
+	if decodeErr != nil {
+		t.Fatalf("callback.Decode() error = %v", decodeErr)
+	}
+
+	options = parseListOptions(data)
+	if options.Page != 3 || options.SortOrder != SortByPrice {
+		t.Errorf("parseListOptions() = %+v, want page 3 sorted by price", options)
+	}
+}
+
+func Test_priceagentDetailBackButton(t *testing.T) {
+	tests := []struct {
+		name       string
+		entityType geizhals.EntityType
+		options    priceagentListOptions
+		want       callback.Data
+	}{
+		{
+			name:       "Product list page and sort order",
+			entityType: geizhals.Product,
+			options:    priceagentListOptions{Page: 2, SortOrder: SortByPrice},
+			want:       callback.New(ShowProductPriceagentsState).With(FieldPage, 2).With(FieldSort, SortByPrice),
+		},
+		{
+			name:       "Wishlist list defaults",
+			entityType: geizhals.Wishlist,
+			options:    priceagentListOptions{SortOrder: SortByName},
+			want:       callback.New(ShowWishlistPriceagentsState).With(FieldPage, 0).With(FieldSort, SortByName),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceagent := models.PriceAgent{ID: 1, Name: "Test", Location: "de", Entity: geizhals.Entity{ID: 1, Type: tt.entityType}}
+
+			// The detail button of the list carries the options to the detail menu
+			detailKeyboard := generateEntityKeyboard([]models.PriceAgent{priceagent}, callback.New(ShowPriceagentDetailState).With(FieldPage, tt.options.Page).With(FieldSort, tt.options.SortOrder), 2)
+
+			detailData, decodeErr := callback.Decode(detailKeyboard.InlineKeyboard[0][0].CallbackData)
+			if decodeErr != nil {
+				t.Fatalf("failed to decode detail button: %s", decodeErr)
+			}
+
+			_, markup := priceagentDetailMessage(priceagent, parseListOptions(detailData))
+
+			var backData string
+
+			for _, row := range markup.InlineKeyboard {
+				for _, button := range row {
+					if button.Text == "↩️ Zurück" {
+						backData = button.CallbackData
+					}
+				}
+			}
+
+			if want := tt.want.MustEncode(); backData != want {
+				t.Errorf("back button = %q, want %q", backData, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// defaultComparisonMargin is the margin in percent used if the config can't be read
+const defaultComparisonMargin = 5.0
+
+var locationNames = map[string]string{
+	"de": "🇩🇪 Deutschland",
+	"at": "🇦🇹 Österreich",
+	"eu": "🇪🇺 EU",
+	"uk": "🇬🇧 Großbritannien",
+	"pl": "🇵🇱 Polen",
+}
+
+// comparisonMargin returns the minimum difference in percent for another location to be reported as cheaper.
+func comparisonMargin() float64 {
+	conf, confErr := config.GetConfig()
+	if confErr != nil {
+		log.Println("Error while reading config file: ", confErr)
+		return defaultComparisonMargin
+	}
+
+	if conf.PriceComparison.MarginPercent == nil {
+		return defaultComparisonMargin
+	}
+
+	return *conf.PriceComparison.MarginPercent
+}
+
+// cheaperLocation returns the cheapest of the given prices, if it is cheaper than the home price by more than the
+// given margin in percent. Prices in other currencies are converted to euros. Locations without a price are ignored.
+func cheaperLocation(home geizhals.EntityPrice, others []geizhals.EntityPrice, marginPercent float64) (geizhals.EntityPrice, bool) {
+	if home.Price <= 0 {
+		return geizhals.EntityPrice{}, false
+	}
+
+	threshold := geizhals.ToEUR(home.Price, home.Currency) * (1 - marginPercent/100)
+
+	var (
+		cheapest      geizhals.EntityPrice
+		cheapestFound bool
+	)
+
+	for _, other := range others {
+		if other.Price <= 0 || geizhals.ToEUR(other.Price, other.Currency) >= threshold {
+			continue
+		}
+
+		if !cheapestFound || geizhals.ToEUR(other.Price, other.Currency) < geizhals.ToEUR(cheapest.Price, cheapest.Currency) {
+			cheapest = other
+			cheapestFound = true
+		}
+	}
+
+	return cheapest, cheapestFound
+}
+
+// comparePrices returns the prices of the compared locations of the price agent.
+func comparePrices(priceagent models.PriceAgent) []geizhals.EntityPrice {
+	locations := priceagent.GetCompareLocations()
+	prices := make([]geizhals.EntityPrice, 0, len(locations))
+
+	for _, location := range locations {
+		prices = append(prices, priceagent.Entity.GetPrice(location))
+	}
+
+	return prices
+}
+
+// formatComparePrice formats the price of another location including the price in euros and the difference to the
+// home price in percent.
+func formatComparePrice(home, other geizhals.EntityPrice) string {
+	if other.Price <= 0 {
+		return "kein Preis"
+	}
+
+	text := createPrice(other.Price, other.Currency.String())
+
+	otherEUR := geizhals.ToEUR(other.Price, other.Currency)
+	if other.Currency != geizhals.EUR {
+		text += fmt.Sprintf(" (≈ %s)", createPrice(otherEUR, geizhals.EUR.String()))
+	}
+
+	if home.Price > 0 {
+		homeEUR := geizhals.ToEUR(home.Price, home.Currency)
+		text += fmt.Sprintf(" %+.1f %%", (otherEUR-homeEUR)/homeEUR*100)
+	}
+
+	return text
+}
+
+// comparisonTableText generates the comparison of the prices of the price agent's location with the compared locations.
+func comparisonTableText(priceagent models.PriceAgent) string {
+	locations := priceagent.GetCompareLocations()
+	if len(locations) == 0 {
+		return ""
+	}
+
+	home := priceagent.CurrentEntityPrice()
+
+	var sb strings.Builder
+
+	sb.WriteString("\n\n🌍 Preisvergleich:")
+	sb.WriteString(fmt.Sprintf("\n%s: %s", locationNames[priceagent.Location], bold(home.String())))
+
+	for _, other := range comparePrices(priceagent) {
+		sb.WriteString(fmt.Sprintf("\n%s: %s", locationNames[other.Location], formatComparePrice(home, other)))
+	}
+
+	return sb.String()
+}
+
+// comparisonNotificationMessage generates the text of the notification about another location being cheaper.
+func comparisonNotificationMessage(priceagent models.PriceAgent, home, cheaper geizhals.EntityPrice) string {
+	entityLink := createLink(priceagent.EntityURL(), priceagent.Entity.Name)
+	cheaperLink := createLink(priceagent.Entity.FullURL(cheaper.Location), locationNames[cheaper.Location])
+
+	return fmt.Sprintf("%s ist in %s günstiger: %s\n\n%s: %s", entityLink, cheaperLink, bold(formatComparePrice(home, cheaper)), locationNames[home.Location], home.String())
+}
+
+// updateComparePrices updates the prices of the compared locations of a product price agent. The chat is notified once
+// another location becomes cheaper than the location of the price agent by more than the configured margin.
+func updateComparePrices(priceAgent models.PriceAgent, homePrice float64, priceStore *tempPriceStore) {
+	locations := priceAgent.GetCompareLocations()
+	if len(locations) == 0 || priceAgent.Entity.Type != geizhals.Product {
+		return
+	}
+
+	oldHome := priceAgent.CurrentEntityPrice()
+	home := oldHome
+	home.Price = homePrice
+
+	oldPrices := comparePrices(priceAgent)
+	newPrices := make([]geizhals.EntityPrice, 0, len(locations))
+
+	for _, oldPrice := range oldPrices {
+		newPrice := oldPrice
+
+		price, isCached := priceStore.getPrice(priceAgent.EntityID, oldPrice.Location)
+		if !isCached {
+			updatedPrice, updateErr := geizhals.UpdateEntityPrice(priceAgent.Entity, oldPrice.Location)
+			if updateErr != nil {
+				log.Println("Error updating compared price:", updateErr)
+				continue
+			}
+
+			if updatedPrice.Price != oldPrice.Price {
+				database.UpdateEntityPrice(updatedPrice)
+			}
+
+			price = updatedPrice.Price
+			priceStore.storePrice(priceAgent.EntityID, oldPrice.Location, price)
+		}
+
+		newPrice.Price = price
+		newPrices = append(newPrices, newPrice)
+	}
+
+	margin := comparisonMargin()
+
+	// Only the change to a cheaper location is reported, not every update while another location stays cheaper
+	_, wasCheaper := cheaperLocation(oldHome, oldPrices, margin)
+	cheaper, isCheaper := cheaperLocation(home, newPrices, margin)
+
+	if !isCheaper || wasCheaper {
+		return
+	}
+
+	sendNotification(priceAgent, comparisonNotificationMessage(priceAgent, home, cheaper))
+}
+
+// compareLocationsMessage generates the text and keyboard of the menu to select the locations to compare.
+func compareLocationsMessage(priceagent models.PriceAgent) (string, gotgbot.InlineKeyboardMarkup) {
+	text := fmt.Sprintf("Mit welchen Ländern möchtest du die Preise von %s vergleichen?\n\nDu wirst benachrichtigt, sobald ein anderes Land um mehr als %.0f %% günstiger ist.", createLink(priceagent.EntityURL(), priceagent.Name), comparisonMargin())
+	text += comparisonTableText(priceagent)
+
+	compareLocations := priceagent.GetCompareLocations()
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+
+	for _, location := range allowedLocations {
+		if location == priceagent.Location {
+			continue
+		}
+
+		buttonText := fmt.Sprintf("☑️ %s", locationNames[location])
+		if slices.Contains(compareLocations, location) {
+			buttonText = fmt.Sprintf("✅ %s", locationNames[location])
+		}
+
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         buttonText,
+			CallbackData: callback.New(ToggleCompareLocationState).With(callback.FieldID, priceagent.ID).With(FieldLocation, location).MustEncode(),
+		}})
+	}
+
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+		{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+	})
+
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// showCompareLocationsHandler handles the callback for the price comparison button of a product price agent.
+func showCompareLocationsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("showCompareLocationsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if priceagent.Entity.Type != geizhals.Product {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("price agent %d is not a product", priceagent.ID))
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showCompareLocationsHandler: failed to answer callback query: %w", err)
+	}
+
+	editedText, markup := compareLocationsMessage(priceagent)
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if err != nil {
+		return fmt.Errorf("showCompareLocationsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// toggleCompareLocationHandler handles the callback for the location buttons of the price comparison menu.
+// The price of an added location is downloaded right away, so that the comparison is complete.
+func toggleCompareLocationHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("toggleCompareLocationHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	location := data.Get(FieldLocation)
+	if !isAllowedLocation(location) || location == priceagent.Location || priceagent.Entity.Type != geizhals.Product {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("invalid compare location '%s'", location))
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	compareLocations := priceagent.GetCompareLocations()
+	if slices.Contains(compareLocations, location) {
+		compareLocations = slices.DeleteFunc(compareLocations, func(l string) bool { return l == location })
+	} else {
+		price, downloadErr := geizhals.UpdateEntityPrice(priceagent.Entity, location)
+		if downloadErr != nil {
+			_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Der Preis konnte nicht geladen werden!", ShowAlert: true})
+			return fmt.Errorf("toggleCompareLocationHandler: %w", downloadErr)
+		}
+
+		database.UpdateEntityPrice(price)
+		priceagent.Entity.Prices = slices.DeleteFunc(priceagent.Entity.Prices, func(p geizhals.EntityPrice) bool { return p.Location == location })
+		priceagent.Entity.Prices = append(priceagent.Entity.Prices, price)
+
+		compareLocations = append(compareLocations, location)
+	}
+
+	if dbErr := database.SetCompareLocations(ctx.EffectiveChat.Id, priceagent.ID, compareLocations); dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("toggleCompareLocationHandler: %w", dbErr)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("toggleCompareLocationHandler: failed to answer callback query: %w", err)
+	}
+
+	priceagent.CompareLocations = strings.Join(compareLocations, ",")
+	editedText, markup := compareLocationsMessage(priceagent)
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if err != nil {
+		return fmt.Errorf("toggleCompareLocationHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
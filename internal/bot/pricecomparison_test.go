@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_cheaperLocation(t *testing.T) {
+	home := geizhals.EntityPrice{Location: "de", Price: 100, Currency: geizhals.EUR}
+
+	tests := []struct {
+		name      string
+		home      geizhals.EntityPrice
+		others    []geizhals.EntityPrice
+		want      string
+		wantFound bool
+	}{
+		{
+			name:   "Within margin",
+			home:   home,
+			others: []geizhals.EntityPrice{{Location: "at", Price: 96, Currency: geizhals.EUR}},
+		},
+		{
+			name: "Cheapest beyond margin",
+			home: home,
+			others: []geizhals.EntityPrice{
+				{Location: "at", Price: 90, Currency: geizhals.EUR},
+				{Location: "pl", Price: 300, Currency: geizhals.PLN},
+			},
+			want:      "pl",
+			wantFound: true,
+		},
+		{
+			name: "Converted price is more expensive",
+			home: home,
+			others: []geizhals.EntityPrice{
+				{Location: "uk", Price: 90, Currency: geizhals.GBP},
+			},
+		},
+		{
+			name:   "Location without price",
+			home:   home,
+			others: []geizhals.EntityPrice{{Location: "at", Price: 0, Currency: geizhals.EUR}},
+		},
+		{
+			name:   "Home without price",
+			home:   geizhals.EntityPrice{Location: "de", Currency: geizhals.EUR},
+			others: []geizhals.EntityPrice{{Location: "at", Price: 90, Currency: geizhals.EUR}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := cheaperLocation(tt.home, tt.others, 5)
+			if found != tt.wantFound || got.Location != tt.want {
+				t.Errorf("cheaperLocation() = (%v, %v), want (%v, %v)", got.Location, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func Test_comparisonTableText(t *testing.T) {
+	priceagent := models.PriceAgent{
+		Location:         "de",
+		CompareLocations: "at,uk,pl",
+		Entity: geizhals.Entity{ID: 1, Type: geizhals.Product, Prices: []geizhals.EntityPrice{
+			{Location: "de", Price: 100, Currency: geizhals.EUR},
+			{Location: "at", Price: 95, Currency: geizhals.EUR},
+			{Location: "uk", Price: 100, Currency: geizhals.GBP},
+		}},
+	}
+
+	want := "\n\n🌍 Preisvergleich:" +
+		"\n🇩🇪 Deutschland: <b>100.00 €</b>" +
+		"\n🇦🇹 Österreich: 95.00 € -5.0 %" +
+		"\n🇬🇧 Großbritannien: 100.00 £ (≈ 117.00 €) +17.0 %" +
+		"\n🇵🇱 Polen: kein Preis"
+
+	if got := comparisonTableText(priceagent); got != want {
+		t.Errorf("comparisonTableText() = %q, want %q", got, want)
+	}
+
+	if got := comparisonTableText(models.PriceAgent{Location: "de"}); got != "" {
+		t.Errorf("comparisonTableText() = %q, want empty text", got)
+	}
+}
@@ -0,0 +1,17 @@
+package bot
+
+import (
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// priceHandler handles the /price command. It looks up the current price of a product or wishlist
+// without creating a price agent.
+func priceHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	if len(extractEntityURLs(ctx.EffectiveMessage)) == 0 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /price <url>", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	return textEntityPreviewHandler(bot, ctx)
+}
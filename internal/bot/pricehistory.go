@@ -8,6 +8,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
@@ -32,10 +33,12 @@ func showPriceHistoryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 	isDarkmode := database.GetDarkmode(ctx.EffectiveUser.Id)
 	dateRangeKeyboard, since := generateDateRangeKeyboard(priceagent, "03", isDarkmode)
+	// The chart is a photo, which can't be edited into the text message of the detail menu
+	photoBackCallback := callback.New(ShowPriceagentDetailState).With(callback.FieldID, priceagent.ID).With(FieldDisplay, Menu1).MustEncode()
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			dateRangeKeyboard,
-			{{Text: "↩️ Zurück", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, priceagent.ID)}},
+			{{Text: "↩️ Zurück", CallbackData: photoBackCallback}},
 		},
 	}
 
@@ -58,11 +61,13 @@ func showPriceHistoryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 	editedText := fmt.Sprintf("%s\nFür welchen Zeitraum möchtest du die Preishistorie sehen?", bold(createLink(priceagent.EntityURL(), priceagent.Name)))
 	inputFile := gotgbot.InputFileByReader("chart.png", buffer)
-	_, sendErr := bot.SendPhoto(ctx.EffectiveUser.Id, inputFile, &gotgbot.SendPhotoOpts{Caption: editedText, ReplyMarkup: markup, ParseMode: "HTML"})
+	chartMessage, sendErr := bot.SendPhoto(ctx.EffectiveChat.Id, inputFile, &gotgbot.SendPhotoOpts{Caption: editedText, ReplyMarkup: markup, ParseMode: "HTML"})
 	if sendErr != nil {
 		return fmt.Errorf("showPriceagentDetail: failed to send photo: %w", sendErr)
 	}
 
+	storeChartFromMessage(chartKey{entityID: priceagent.EntityID, location: priceagent.Location, dateRange: "03", darkMode: isDarkmode}, chartMessage)
+
 	return nil
 }
 
@@ -71,15 +76,14 @@ func showPriceHistoryHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 func updatePriceHistoryGraphHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	cbq := ctx.Update.CallbackQuery
 
-	menu, priceagent, parseErr := parseMenuPriceagent(ctx)
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
 	if parseErr != nil {
 		return fmt.Errorf("updatePriceHistoryGraphHandler: failed to parse callback data: %w", parseErr)
 	}
 
 	darkMode := database.GetDarkmode(ctx.EffectiveUser.Id)
 
-	if menu.Extra != "" {
-		changeDarkmodeTo := menu.Extra
+	if changeDarkmodeTo := data.Get(FieldDarkMode); changeDarkmodeTo != "" {
 		switch changeDarkmodeTo {
 		case "0":
 			darkMode = false
@@ -90,13 +94,15 @@ func updatePriceHistoryGraphHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 	database.UpdateDarkMode(ctx.EffectiveUser.Id, darkMode)
 
-	dateRange := menu.SubMenu
+	dateRange := data.Get(FieldRange)
 	dateRangeKeyboard, since := generateDateRangeKeyboard(priceagent, dateRange, darkMode)
 
+	// The chart is a photo, which can't be edited into the text message of the detail menu
+	photoBackCallback := callback.New(ShowPriceagentDetailState).With(callback.FieldID, priceagent.ID).With(FieldDisplay, Menu1).MustEncode()
 	markup := gotgbot.InlineKeyboardMarkup{
 		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 			dateRangeKeyboard,
-			{{Text: "↩️ Zurück", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, priceagent.ID)}},
+			{{Text: "↩️ Zurück", CallbackData: photoBackCallback}},
 		},
 	}
 
@@ -120,11 +126,13 @@ func updatePriceHistoryGraphHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	caption := fmt.Sprintf("%s\nFür welchen Zeitraum möchtest du die Preishistorie sehen?", bold(createLink(priceagent.EntityURL(), priceagent.Name)))
 	inputFile := gotgbot.InputFileByReader("chart.png", buffer)
 	newPic := gotgbot.InputMediaPhoto{Media: inputFile, Caption: caption, ParseMode: "HTML"}
-	_, _, sendErr := cbq.Message.EditMedia(bot, newPic, &gotgbot.EditMessageMediaOpts{ReplyMarkup: markup})
+	chartMessage, _, sendErr := cbq.Message.EditMedia(bot, newPic, &gotgbot.EditMessageMediaOpts{ReplyMarkup: markup})
 	if sendErr != nil {
 		return fmt.Errorf("updatePriceHistoryGraphHandler: failed to send photo: %w", sendErr)
 	}
 
+	storeChartFromMessage(chartKey{entityID: priceagent.EntityID, location: priceagent.Location, dateRange: dateRange, darkMode: darkMode}, chartMessage)
+
 	return nil
 }
 
@@ -138,12 +146,13 @@ func generateDateRangeKeyboard(priceagent models.PriceAgent, dateRange string, i
 		switchTheme = 0
 	}
 
+	graphData := callback.New(UpdateHistoryGraphState).With(callback.FieldID, priceagent.ID)
 	dateRangeKeyboard := []gotgbot.InlineKeyboardButton{
-		{Text: "1M", CallbackData: fmt.Sprintf("%s_%d", UpdateHistoryGraph1State, priceagent.ID)},
-		{Text: "3M", CallbackData: fmt.Sprintf("%s_%d", UpdateHistoryGraph3State, priceagent.ID)},
-		{Text: "6M", CallbackData: fmt.Sprintf("%s_%d", UpdateHistoryGraph6State, priceagent.ID)},
-		{Text: "12M", CallbackData: fmt.Sprintf("%s_%d", UpdateHistoryGraph12State, priceagent.ID)},
-		{Text: themeButton, CallbackData: fmt.Sprintf("m05_%s_%d_%d", dateRange, priceagent.ID, switchTheme)},
+		{Text: "1M", CallbackData: graphData.With(FieldRange, "01").MustEncode()},
+		{Text: "3M", CallbackData: graphData.With(FieldRange, "03").MustEncode()},
+		{Text: "6M", CallbackData: graphData.With(FieldRange, "06").MustEncode()},
+		{Text: "12M", CallbackData: graphData.With(FieldRange, "12").MustEncode()},
+		{Text: themeButton, CallbackData: graphData.With(FieldRange, dateRange).With(FieldDarkMode, switchTheme).MustEncode()},
 	}
 
 	var since time.Time
@@ -0,0 +1,223 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+)
+
+// Sources of the price agent limit of a chat, shown to admins
+const (
+	quotaSourceOverride = "individuell"
+	quotaSourceTier     = "Tarif"
+	quotaSourceDefault  = "Standardtarif"
+	quotaSourceConfig   = "Konfiguration"
+)
+
+// quotaLimit resolves the maximum number of price agents of a chat. An individual limit of the chat takes precedence
+// over the tier of the chat, followed by the default tier and finally the limit of the config.
+// It returns the limit and where it originates from.
+func quotaLimit(quota models.ChatQuota, defaultTier *models.Tier, configLimit int64) (int64, string) {
+	switch {
+	case quota.MaxPriceAgents != nil:
+		return *quota.MaxPriceAgents, quotaSourceOverride
+	case quota.Tier != nil:
+		return quota.Tier.MaxPriceAgents, fmt.Sprintf("%s '%s'", quotaSourceTier, quota.Tier.Name)
+	case defaultTier != nil:
+		return defaultTier.MaxPriceAgents, fmt.Sprintf("%s '%s'", quotaSourceDefault, defaultTier.Name)
+	}
+
+	return configLimit, quotaSourceConfig
+}
+
+// priceagentLimit returns the maximum number of price agents of the given chat and where the limit originates from.
+func priceagentLimit(chatID int64) (int64, string, error) {
+	conf, confErr := config.GetConfig()
+	if confErr != nil {
+		return 0, "", fmt.Errorf("priceagentLimit: failed to get config: %w", confErr)
+	}
+
+	quota, quotaErr := database.GetChatQuota(chatID)
+	if quotaErr != nil {
+		return 0, "", fmt.Errorf("priceagentLimit: %w", quotaErr)
+	}
+
+	var defaultTier *models.Tier
+
+	tier, tierErr := database.GetTierByName(models.DefaultTierName)
+	switch {
+	case tierErr == nil:
+		defaultTier = &tier
+	case !errors.Is(tierErr, database.ErrNotFound):
+		return 0, "", fmt.Errorf("priceagentLimit: %w", tierErr)
+	}
+
+	limit, source := quotaLimit(quota, defaultTier, conf.MaxPriceAgents)
+
+	return limit, source, nil
+}
+
+// adminTiersText generates the text of /admin tiers listing the given tiers.
+func adminTiersText(tiers []models.Tier, configLimit int64) string {
+	var sb strings.Builder
+
+	sb.WriteString("🏷️ Tarife\n")
+
+	if len(tiers) == 0 {
+		sb.WriteString("\nEs sind keine Tarife angelegt.")
+	}
+
+	for _, tier := range tiers {
+		sb.WriteString(fmt.Sprintf("\n• %s: %d Preisagenten", tier.Name, tier.MaxPriceAgents))
+	}
+
+	if !slices.ContainsFunc(tiers, func(tier models.Tier) bool { return tier.Name == models.DefaultTierName }) {
+		sb.WriteString(fmt.Sprintf("\n\nOhne Tarif '%s' gilt das Limit der Konfiguration: %d Preisagenten", models.DefaultTierName, configLimit))
+	}
+
+	return sb.String()
+}
+
+// adminTiersCommand lists all tiers for /admin tiers.
+func adminTiersCommand() string {
+	conf, confErr := config.GetConfig()
+	if confErr != nil {
+		log.Println("Error while reading config file: ", confErr)
+		return "Es ist ein Fehler beim Laden der Konfiguration aufgetreten!"
+	}
+
+	tiers, dbErr := database.GetTiers()
+	if dbErr != nil {
+		log.Printf("adminTiersCommand: %s\n", dbErr)
+		return "Es ist ein Fehler beim Laden der Tarife aufgetreten!"
+	}
+
+	return adminTiersText(tiers, conf.MaxPriceAgents)
+}
+
+// parseQuotaLimit parses a limit of price agents given by an admin.
+func parseQuotaLimit(value string) (int64, error) {
+	limit, parseErr := strconv.ParseInt(value, 10, 64)
+	if parseErr != nil {
+		return 0, fmt.Errorf("parseQuotaLimit: %w", parseErr)
+	}
+
+	if limit < 0 {
+		return 0, fmt.Errorf("parseQuotaLimit: negative limit %d", limit)
+	}
+
+	return limit, nil
+}
+
+// adminTierCommand creates, updates or deletes a tier for /admin tier <name> <limit|delete>.
+func adminTierCommand(adminID int64, args []string) string {
+	if len(args) < 2 {
+		return adminUsage
+	}
+
+	name := args[0]
+
+	if args[1] == "delete" {
+		if dbErr := database.DeleteTier(name); dbErr != nil {
+			if errors.Is(dbErr, database.ErrNotFound) {
+				return fmt.Sprintf("Der Tarif '%s' existiert nicht.", name)
+			}
+
+			log.Printf("adminTierCommand: %s\n", dbErr)
+
+			return "Es ist ein Fehler beim Löschen des Tarifs aufgetreten!"
+		}
+
+		log.Printf("Admin %d deleted tier '%s'\n", adminID, name)
+
+		return fmt.Sprintf("Der Tarif '%s' wurde gelöscht. Chats mit diesem Tarif nutzen nun den Standardtarif.", name)
+	}
+
+	limit, parseErr := parseQuotaLimit(args[1])
+	if parseErr != nil {
+		return "Bitte gib ein gültiges Limit an!"
+	}
+
+	if dbErr := database.SaveTier(name, limit); dbErr != nil {
+		log.Printf("adminTierCommand: %s\n", dbErr)
+		return "Es ist ein Fehler beim Speichern des Tarifs aufgetreten!"
+	}
+
+	log.Printf("Admin %d set tier '%s' to %d price agents\n", adminID, name, limit)
+
+	return fmt.Sprintf("Der Tarif '%s' erlaubt nun %d Preisagenten.", name, limit)
+}
+
+// assignTier assigns the given tier to the quota of a chat. An individual limit of the chat is removed, as it would
+// take precedence over the tier.
+func assignTier(quota models.ChatQuota, tier models.Tier) models.ChatQuota {
+	quota.TierID = &tier.ID
+	quota.Tier = &tier
+	quota.MaxPriceAgents = nil
+
+	return quota
+}
+
+// adminQuotaCommand shows or changes the limit of a chat for /admin quota <id> [tier <name>|limit <n>|reset].
+func adminQuotaCommand(adminID int64, args []string) string {
+	if len(args) < 1 {
+		return adminUsage
+	}
+
+	chatID, parseErr := strconv.ParseInt(args[0], 10, 64)
+	if parseErr != nil {
+		return "Bitte gib eine gültige ID an!"
+	}
+
+	if len(args) > 1 {
+		quota, dbErr := database.GetChatQuota(chatID)
+		if dbErr != nil {
+			log.Printf("adminQuotaCommand: %s\n", dbErr)
+			return "Es ist ein Fehler beim Laden des Limits aufgetreten!"
+		}
+
+		switch {
+		case args[1] == "tier" && len(args) > 2:
+			tier, tierErr := database.GetTierByName(args[2])
+			if tierErr != nil {
+				return fmt.Sprintf("Der Tarif '%s' existiert nicht.", args[2])
+			}
+
+			quota = assignTier(quota, tier)
+		case args[1] == "limit" && len(args) > 2:
+			limit, limitErr := parseQuotaLimit(args[2])
+			if limitErr != nil {
+				return "Bitte gib ein gültiges Limit an!"
+			}
+
+			quota.MaxPriceAgents = &limit
+		case args[1] == "reset":
+			quota.TierID = nil
+			quota.MaxPriceAgents = nil
+		default:
+			return adminUsage
+		}
+
+		if dbErr := database.SaveChatQuota(quota); dbErr != nil {
+			log.Printf("adminQuotaCommand: %s\n", dbErr)
+			return "Es ist ein Fehler beim Speichern des Limits aufgetreten!"
+		}
+
+		log.Printf("Admin %d changed the quota of chat %d: %s\n", adminID, chatID, strings.Join(args[1:], " "))
+	}
+
+	limit, source, limitErr := priceagentLimit(chatID)
+	if limitErr != nil {
+		log.Printf("adminQuotaCommand: %s\n", limitErr)
+		return "Es ist ein Fehler beim Laden des Limits aufgetreten!"
+	}
+
+	return fmt.Sprintf("Chat %d: %d von %d Preisagenten (%s)", chatID, database.GetPriceAgentCountForChat(chatID), limit, source)
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+)
+
+func Test_quotaLimit(t *testing.T) {
+	override := int64(0)
+	team := &models.Tier{ID: 2, Name: "team", MaxPriceAgents: 100}
+	defaultTier := &models.Tier{ID: 1, Name: models.DefaultTierName, MaxPriceAgents: 10}
+
+	tests := []struct {
+		name        string
+		quota       models.ChatQuota
+		defaultTier *models.Tier
+		wantLimit   int64
+		wantSource  string
+	}{
+		{
+			name:       "Config without tiers",
+			wantLimit:  5,
+			wantSource: quotaSourceConfig,
+		},
+		{
+			name:        "Default tier",
+			defaultTier: defaultTier,
+			wantLimit:   10,
+			wantSource:  "Standardtarif 'default'",
+		},
+		{
+			name:        "Tier of the chat",
+			quota:       models.ChatQuota{TierID: &team.ID, Tier: team},
+			defaultTier: defaultTier,
+			wantLimit:   100,
+			wantSource:  "Tarif 'team'",
+		},
+		{
+			name:        "Override takes precedence",
+			quota:       models.ChatQuota{TierID: &team.ID, Tier: team, MaxPriceAgents: &override},
+			defaultTier: defaultTier,
+			wantLimit:   0,
+			wantSource:  quotaSourceOverride,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, source := quotaLimit(tt.quota, tt.defaultTier, 5)
+			if limit != tt.wantLimit || source != tt.wantSource {
+				t.Errorf("quotaLimit() = (%d, %s), want (%d, %s)", limit, source, tt.wantLimit, tt.wantSource)
+			}
+		})
+	}
+}
+
+func Test_assignTier(t *testing.T) {
+	override := int64(3)
+	oldTierID := int64(1)
+	team := models.Tier{ID: 2, Name: "team", MaxPriceAgents: 100}
+
+	tests := []struct {
+		name  string
+		quota models.ChatQuota
+	}{
+		{"Chat without quota", models.ChatQuota{ChatID: 42}},
+		{"Chat with another tier", models.ChatQuota{ChatID: 42, TierID: &oldTierID}},
+		{"Individual limit is removed", models.ChatQuota{ChatID: 42, TierID: &oldTierID, MaxPriceAgents: &override}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignTier(tt.quota, team)
+			if got.ChatID != tt.quota.ChatID || got.TierID == nil || *got.TierID != team.ID || got.MaxPriceAgents != nil {
+				t.Errorf("assignTier() = %+v, want tier %d without individual limit", got, team.ID)
+			}
+
+			limit, source := quotaLimit(got, nil, 5)
+			if limit != team.MaxPriceAgents || source != "Tarif 'team'" {
+				t.Errorf("quotaLimit() = (%d, %s), want (%d, Tarif 'team')", limit, source, team.MaxPriceAgents)
+			}
+		})
+	}
+}
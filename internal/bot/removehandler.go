@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// removeHandler handles the /remove command. It deletes a price agent given by its ID or (part of) its name.
+func removeHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	args := ctx.Args()
+	if len(args) < 2 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /remove <id|name>", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	query := strings.Join(args[1:], " ")
+
+	matches, findErr := findPriceagents(ctx.EffectiveChat.Id, query)
+	if findErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten! Bitte probiere es später erneut!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("removeHandler: %w", findErr)
+	}
+
+	if len(matches) == 0 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es wurde kein passender Preisagent gefunden! Mit /list siehst du alle deine Preisagenten.", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	if len(matches) > 1 {
+		lines := make([]string, 0, len(matches))
+		for _, priceagent := range matches {
+			lines = append(lines, fmt.Sprintf("%s %s", bold(fmt.Sprintf("#%d", priceagent.ID)), createLink(priceagent.EntityURL(), priceagent.Name)))
+		}
+
+		text := fmt.Sprintf("Es wurden mehrere passende Preisagenten gefunden. Bitte gib die ID an:\n\n%s", strings.Join(lines, "\n"))
+		_, _ = ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+
+		return nil
+	}
+
+	priceagent := matches[0]
+
+	if deleteErr := database.DeletePriceAgent(priceagent); deleteErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Der Preisagent konnte nicht gelöscht werden!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("removeHandler: failed to delete priceagent from database: %w", deleteErr)
+	}
+
+	text := fmt.Sprintf("Preisagent für %s wurde gelöscht!", bold(createLink(priceagent.EntityURL(), priceagent.Name)))
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if replyErr != nil {
+		return fmt.Errorf("removeHandler: failed to send reply: %w", replyErr)
+	}
+
+	return nil
+}
+
+// findPriceagents returns the price agents of a chat matching the given query. The query is either
+// the ID of a price agent (optionally prefixed with '#') or a case-insensitive part of its name.
+func findPriceagents(chatID int64, query string) ([]models.PriceAgent, error) {
+	if priceagentID, parseErr := strconv.ParseInt(strings.TrimPrefix(query, "#"), 10, 64); parseErr == nil {
+		priceagent, dbErr := database.GetPriceagentForChatByID(chatID, priceagentID)
+		if dbErr != nil {
+			// Not finding a price agent is not an error for the caller
+			return nil, nil //nolint:nilerr
+		}
+
+		return []models.PriceAgent{priceagent}, nil
+	}
+
+	priceagents, dbErr := database.GetPriceagentsForChat(chatID)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+
+	query = strings.ToLower(query)
+
+	var matches []models.PriceAgent
+
+	for _, priceagent := range priceagents {
+		name := strings.ToLower(priceagent.Name)
+		if name == query {
+			// An exact match always wins
+			return []models.PriceAgent{priceagent}, nil
+		}
+
+		if strings.Contains(name, query) {
+			matches = append(matches, priceagent)
+		}
+	}
+
+	return matches, nil
+}
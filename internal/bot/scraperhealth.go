@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/prometheus"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// scraperHealthCheckInterval is the interval at which the health of the scraper is checked in the background
+const scraperHealthCheckInterval = time.Minute
+
+var (
+	// scraperHealthAlerted is set while the admins are alerted about a high failure rate of the scraper.
+	scraperHealthAlerted bool
+	// scraperHealthMu guards scraperHealthAlerted, the health is checked by the background job and after price updates.
+	scraperHealthMu sync.Mutex
+)
+
+// scraperHealthMessage decides whether the admins have to be alerted about the health of the scraper. An alert is
+// generated once the failure rate crosses the threshold and a recovery message once it falls below it again. Windows
+// with less than minScrapes scrapes don't change the state. It returns the new state and the text to send, if any.
+func scraperHealthMessage(stats geizhals.ScrapeStats, alerted bool, minScrapes int, thresholdPercent float64) (bool, string) {
+	if stats.Total < minScrapes {
+		return alerted, ""
+	}
+
+	failureRate := stats.FailureRate() * 100
+	unhealthy := failureRate >= thresholdPercent
+
+	summary := fmt.Sprintf("%d von %d Abrufen der letzten %d Minuten sind fehlgeschlagen (%.1f %%)", stats.Failures(), stats.Total, int(stats.Window.Minutes()), failureRate)
+
+	switch {
+	case unhealthy && !alerted:
+		text := fmt.Sprintf("🚨 Scraper-Warnung: %s.\n\n• HTTP-Fehler: %d\n• Element nicht gefunden: %d\n• Preis nicht lesbar: %d\n\nMöglicherweise hat Geizhals das Layout geändert.",
+			summary, stats.Outcomes[geizhals.OutcomeHTTPError], stats.Outcomes[geizhals.OutcomeSelectorMissing], stats.Outcomes[geizhals.OutcomePriceUnparsable])
+		return true, text
+	case !unhealthy && alerted:
+		return false, fmt.Sprintf("✅ Der Scraper funktioniert wieder: %s.", summary)
+	}
+
+	return alerted, ""
+}
+
+// scraperHealthJob checks the health of the scraper at a fixed interval, independent of the price updates.
+// This way the automatic maintenance mode also works for scrapes triggered by users.
+func scraperHealthJob() {
+	for {
+		time.Sleep(scraperHealthCheckInterval)
+		checkScraperHealth()
+	}
+}
+
+// checkScraperHealth updates the failure rate metric and alerts the admins when the health of the scraper changed.
+func checkScraperHealth() {
+	conf, confErr := config.GetConfig()
+	if confErr != nil {
+		log.Println("Error while reading config file: ", confErr)
+		return
+	}
+
+	scraperHealthMu.Lock()
+	defer scraperHealthMu.Unlock()
+
+	stats := geizhals.GetScrapeStats()
+	prometheus.ScrapeFailureRate.Set(stats.FailureRate())
+
+	var text string
+
+	scraperHealthAlerted, text = scraperHealthMessage(stats, scraperHealthAlerted, conf.ScraperHealth.MinScrapes, conf.ScraperHealth.FailureThresholdPercent)
+	if text == "" {
+		return
+	}
+
+	log.Println("Scraper health changed:", text)
+
+	if conf.Maintenance.Automatic {
+		maintenance.setAutomatic(scraperHealthAlerted)
+		text += "\n\n" + maintenanceStatusText(maintenance.status())
+	}
+
+	notifyAdmins(conf.AdminIDs, text)
+}
+
+// notifyAdmins sends the given text to all admins of the bot.
+func notifyAdmins(adminIDs []int64, text string) {
+	for _, adminID := range adminIDs {
+		if _, sendErr := bot.SendMessage(adminID, text, &gotgbot.SendMessageOpts{}); sendErr != nil {
+			log.Printf("Error sending message to admin %d: %s\n", adminID, sendErr)
+		}
+	}
+}
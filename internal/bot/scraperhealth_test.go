@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+)
+
+func Test_scraperHealthMessage(t *testing.T) {
+	healthy := geizhals.ScrapeStats{Window: time.Hour, Total: 40, Outcomes: map[geizhals.ScrapeOutcome]int{geizhals.OutcomeSuccess: 38, geizhals.OutcomeHTTPError: 2}}
+	unhealthy := geizhals.ScrapeStats{Window: time.Hour, Total: 40, Outcomes: map[geizhals.ScrapeOutcome]int{geizhals.OutcomeSuccess: 20, geizhals.OutcomeSelectorMissing: 15, geizhals.OutcomePriceUnparsable: 5}}
+	tooFew := geizhals.ScrapeStats{Window: time.Hour, Total: 5, Outcomes: map[geizhals.ScrapeOutcome]int{geizhals.OutcomeHTTPError: 5}}
+
+	tests := []struct {
+		name        string
+		stats       geizhals.ScrapeStats
+		alerted     bool
+		wantAlerted bool
+		wantText    string
+	}{
+		{
+			name:        "Failure rate crosses threshold",
+			stats:       unhealthy,
+			wantAlerted: true,
+			wantText:    "🚨 Scraper-Warnung: 20 von 40 Abrufen der letzten 60 Minuten sind fehlgeschlagen (50.0 %).\n\n• HTTP-Fehler: 0\n• Element nicht gefunden: 15\n• Preis nicht lesbar: 5\n\nMöglicherweise hat Geizhals das Layout geändert.",
+		},
+		{
+			name:        "Already alerted",
+			stats:       unhealthy,
+			alerted:     true,
+			wantAlerted: true,
+		},
+		{
+			name:     "Recovered",
+			stats:    healthy,
+			alerted:  true,
+			wantText: "✅ Der Scraper funktioniert wieder: 2 von 40 Abrufen der letzten 60 Minuten sind fehlgeschlagen (5.0 %).",
+		},
+		{
+			name:  "Healthy",
+			stats: healthy,
+		},
+		{
+			name:  "Too few scrapes",
+			stats: tooFew,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAlerted, gotText := scraperHealthMessage(tt.stats, tt.alerted, 20, 25)
+			if gotAlerted != tt.wantAlerted || gotText != tt.wantText {
+				t.Errorf("scraperHealthMessage() = (%v, %q), want (%v, %q)", gotAlerted, gotText, tt.wantAlerted, tt.wantText)
+			}
+		})
+	}
+}
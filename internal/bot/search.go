@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	// searchResultLimit is the maximum number of search results shown to the user
+	searchResultLimit = 5
+	// maxSearchQueryLength is the maximum number of characters of a search query
+	maxSearchQueryLength = 100
+	// defaultSearchLocation is the location which is searched if the user doesn't specify one
+	defaultSearchLocation = "de"
+)
+
+// parseSearchQuery splits an optional leading location off the given search query, e.g. "at rtx 4070".
+func parseSearchQuery(text string) (query, location string) {
+	text = strings.TrimSpace(text)
+
+	firstWord, rest, found := strings.Cut(text, " ")
+	if found && isAllowedLocation(strings.ToLower(firstWord)) && strings.TrimSpace(rest) != "" {
+		return strings.TrimSpace(rest), strings.ToLower(firstWord)
+	}
+
+	return text, defaultSearchLocation
+}
+
+// searchResultsMessage generates the text and keyboard listing the given search results.
+// Each result has a button to create a price agent for it.
+func searchResultsMessage(query, location string, results []geizhals.SearchResult) (string, gotgbot.InlineKeyboardMarkup) {
+	var sb strings.Builder
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+
+	if len(results) == 0 {
+		sb.WriteString(fmt.Sprintf("Für %s wurden keine Produkte gefunden.", bold(html.EscapeString(query))))
+	} else {
+		sb.WriteString(fmt.Sprintf("Suchergebnisse für %s:\n", bold(html.EscapeString(query))))
+	}
+
+	for i, result := range results {
+		price := "kein Preis"
+		if result.Price.Price > 0 {
+			price = createPrice(result.Price.Price, result.Price.Currency.String())
+		}
+
+		sb.WriteString(fmt.Sprintf("\n%d. %s\n     %s\n", i+1, createLink(result.FullURL(location), result.Name), bold(price)))
+
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("🆕 %d. %s", i+1, shortenName(result.Name, maxItemButtonNameLength)),
+			CallbackData: entityCallback(SearchCreatePriceagentState, result.EntityID, location),
+		}})
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%s", createLink(geizhals.SearchURL(query, location), "Alle Ergebnisse auf Geizhals")))
+
+	return sb.String(), gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// sendSearchResults searches Geizhals for the given query and replies with the results.
+func sendSearchResults(bot *gotgbot.Bot, ctx *ext.Context, query, location string) error {
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		_, _ = ctx.EffectiveMessage.Reply(bot, fmt.Sprintf("Bitte sende mir einen Suchbegriff mit maximal %d Zeichen!", maxSearchQueryLength), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "typing", nil)
+
+	results, searchErr := geizhals.Search(query, location, searchResultLimit)
+	if searchErr != nil {
+		log.Printf("sendSearchResults: %s\n", searchErr)
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Problem beim Abrufen der Daten aufgetreten! Bitte versuche es später erneut", &gotgbot.SendMessageOpts{})
+
+		return nil
+	}
+
+	text, markup := searchResultsMessage(query, location, results)
+
+	_, replyErr := ctx.EffectiveMessage.Reply(bot, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if replyErr != nil {
+		return fmt.Errorf("sendSearchResults: failed to send search results: %w", replyErr)
+	}
+
+	return nil
+}
+
+// searchHandler handles the /search command: /search [location] <term>
+func searchHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	args := ctx.Args()
+	if len(args) < 2 {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Bitte nutze den Befehl wie folgt: /search <Suchbegriff>", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	query, location := parseSearchQuery(strings.Join(args[1:], " "))
+
+	return sendSearchResults(bot, ctx, query, location)
+}
+
+// searchProductHandler handles the search button of the "new price agent" menu. It asks the user for a search term.
+func searchProductHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("searchProductHandler: failed to answer callback query: %w", err)
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.SearchProduct, ChatID: ctx.EffectiveChat.Id}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "↩️ Zurück", CallbackData: menuCallback(NewPriceAgentState)},
+		},
+	}}
+
+	_, _, err := cbq.Message.EditText(bot, "Wonach möchtest du suchen? Sende mir den Namen des Produkts!", &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
+	if err != nil {
+		return fmt.Errorf("searchProductHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// textSearchProductHandler handles the text message containing the search term of the user.
+func textSearchProductHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.Idle, ChatID: ctx.EffectiveChat.Id}
+
+	query, location := parseSearchQuery(ctx.EffectiveMessage.Text)
+
+	return sendSearchResults(bot, ctx, query, location)
+}
+
+// searchCreatePriceagentHandler handles the buttons below the search results and creates a price agent for the selected product.
+func searchCreatePriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return newStaleButtonError(StaleInvalidData, decodeErr)
+	}
+
+	entityID, idErr := data.Int(callback.FieldID)
+	if idErr != nil {
+		return newStaleButtonError(StaleInvalidData, idErr)
+	}
+
+	location := data.Get(FieldLocation)
+	if !isAllowedLocation(location) {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("invalid location '%s'", location))
+	}
+
+	return createPriceagentFromButton(bot, ctx, geizhals.ProductURL(entityID, location), location)
+}
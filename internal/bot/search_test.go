@@ -0,0 +1,26 @@
+package bot
+
+import "testing"
+
+func Test_parseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantQuery    string
+		wantLocation string
+	}{
+		{name: "Query without location", text: " rtx 4070 ", wantQuery: "rtx 4070", wantLocation: "de"},
+		{name: "Query with location", text: "AT rtx 4070", wantQuery: "rtx 4070", wantLocation: "at"},
+		{name: "Query with EU location", text: "eu rtx 4070", wantQuery: "rtx 4070", wantLocation: "eu"},
+		{name: "Location only", text: "uk", wantQuery: "uk", wantLocation: "de"},
+		{name: "Unknown location", text: "ch rtx 4070", wantQuery: "ch rtx 4070", wantLocation: "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, location := parseSearchQuery(tt.text)
+			if query != tt.wantQuery || location != tt.wantLocation {
+				t.Errorf("parseSearchQuery() = (%v, %v), want (%v, %v)", query, location, tt.wantQuery, tt.wantLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/prometheus"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// Categories of stale buttons. They are used as metric labels, hence they must not change.
+const (
+	StaleInvalidData        = "invalid_data"
+	StaleUnknownAction      = "unknown_action"
+	StalePriceagentNotFound = "priceagent_not_found"
+	StaleEntityNotFound     = "entity_not_found"
+	StaleTagNotFound        = "tag_not_found"
+)
+
+// staleButtonTexts contains the explanations for the stale button categories per language
+var staleButtonTexts = map[string]map[string]string{
+	"de": {
+		StaleInvalidData:        "Dieser Button ist veraltet und funktioniert nicht mehr.",
+		StaleUnknownAction:      "Dieser Button ist veraltet und funktioniert nicht mehr.",
+		StalePriceagentNotFound: "Dieser Preisagent existiert nicht mehr, vielleicht wurde er schon gelöscht?",
+		StaleEntityNotFound:     "Dieses Produkt ist nicht mehr verfügbar.",
+		StaleTagNotFound:        "Dieser Tag existiert nicht mehr.",
+	},
+	"en": {
+		StaleInvalidData:        "This button is outdated and doesn't work anymore.",
+		StaleUnknownAction:      "This button is outdated and doesn't work anymore.",
+		StalePriceagentNotFound: "This price agent doesn't exist anymore, maybe it was already deleted?",
+		StaleEntityNotFound:     "This product isn't available anymore.",
+		StaleTagNotFound:        "This tag doesn't exist anymore.",
+	},
+}
+
+// staleButtonError is returned by the handlers when the callback data of a pressed button refers to
+// something that doesn't exist (anymore), e.g. a deleted price agent.
+type staleButtonError struct {
+	category string
+	err      error
+}
+
+func (e *staleButtonError) Error() string {
+	return fmt.Sprintf("stale button (%s): %s", e.category, e.err)
+}
+
+func (e *staleButtonError) Unwrap() error {
+	return e.err
+}
+
+// newStaleButtonError wraps an error which occurred while loading the data referenced by a button.
+// Records that weren't found are reported as stale button of the given category, other errors are returned as is.
+func newStaleButtonError(category string, err error) error {
+	if category != StaleInvalidData && category != StaleUnknownAction && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+
+	return &staleButtonError{category: category, err: err}
+}
+
+// staleButtonText returns the explanation for a stale button in the language of the user. German is the default.
+func staleButtonText(languageCode, category string) string {
+	texts, ok := staleButtonTexts[strings.ToLower(languageCode)]
+	if !ok {
+		texts = staleButtonTexts["de"]
+	}
+
+	return texts[category]
+}
+
+// handleStaleButton explains to the user that the pressed button doesn't work anymore and replaces the menu
+// of the message with a recovery menu, so that the user isn't stuck with outdated buttons.
+func handleStaleButton(bot *gotgbot.Bot, ctx *ext.Context, category string) error {
+	prometheus.StaleButtons(category).Inc()
+
+	cbq := ctx.CallbackQuery
+	log.Printf("Stale button (%s) pressed by %d: %s\n", category, ctx.EffectiveUser.Id, cbq.Data)
+
+	text := staleButtonText(ctx.EffectiveUser.LanguageCode, category)
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: text, ShowAlert: true}); err != nil {
+		return fmt.Errorf("handleStaleButton: failed to answer callback query: %w", err)
+	}
+
+	// Buttons of inline messages don't belong to a message the bot can edit
+	if cbq.Message == nil {
+		return nil
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "Meine Preisagenten", CallbackData: menuCallback(ViewPriceAgentState)},
+			{Text: "Hauptmenü", CallbackData: menuCallback(MainMenuState)},
+		},
+	}}
+
+	_, _, editErr := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup})
+	if editErr == nil {
+		return nil
+	}
+
+	// Messages without text, e.g. price history charts, only get the recovery buttons
+	if _, _, markupErr := cbq.Message.EditReplyMarkup(bot, &gotgbot.EditMessageReplyMarkupOpts{ReplyMarkup: markup}); markupErr != nil {
+		return fmt.Errorf("handleStaleButton: failed to edit message: %w", markupErr)
+	}
+
+	return nil
+}
+
+// handleUpdateError is called by the dispatcher for all errors returned by the handlers.
+// Stale buttons are handled uniformly, all the other errors are logged.
+func handleUpdateError(bot *gotgbot.Bot, ctx *ext.Context, err error) ext.DispatcherAction {
+	var staleErr *staleButtonError
+	if ctx.CallbackQuery != nil && errors.As(err, &staleErr) {
+		if handleErr := handleStaleButton(bot, ctx, staleErr.category); handleErr != nil {
+			log.Println("an error occurred while handling a stale button:", handleErr.Error())
+		}
+
+		return ext.DispatcherActionNoop
+	}
+
+	log.Println("an error occurred while handling update:", err.Error())
+
+	return ext.DispatcherActionNoop
+}
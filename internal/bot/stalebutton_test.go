@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+)
+
+func Test_newStaleButtonError(t *testing.T) {
+	otherErr := errors.New("database is locked")
+
+	tests := []struct {
+		name      string
+		category  string
+		err       error
+		wantStale bool
+	}{
+		{"price agent not found", StalePriceagentNotFound, database.ErrNotFound, true},
+		{"wrapped not found", StaleTagNotFound, fmt.Errorf("query failed: %w", database.ErrNotFound), true},
+		{"invalid data", StaleInvalidData, otherErr, true},
+		{"other database error", StalePriceagentNotFound, otherErr, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("handler: %w", newStaleButtonError(tt.category, tt.err))
+
+			var staleErr *staleButtonError
+			if isStale := errors.As(err, &staleErr); isStale != tt.wantStale {
+				t.Fatalf("newStaleButtonError() stale = %v, want %v", isStale, tt.wantStale)
+			}
+
+			if tt.wantStale && staleErr.category != tt.category {
+				t.Errorf("newStaleButtonError() category = %s, want %s", staleErr.category, tt.category)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("newStaleButtonError() doesn't wrap the original error")
+			}
+		})
+	}
+}
+
+func Test_staleButtonText(t *testing.T) {
+	if got := staleButtonText("en", StaleTagNotFound); got != staleButtonTexts["en"][StaleTagNotFound] {
+		t.Errorf("staleButtonText() = %q, want english text", got)
+	}
+
+	if got := staleButtonText("fr", StaleTagNotFound); got != staleButtonTexts["de"][StaleTagNotFound] {
+		t.Errorf("staleButtonText() = %q, want german fallback", got)
+	}
+}
@@ -0,0 +1,407 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// maxTagNameLength is the maximum number of characters of a tag name
+const maxTagNameLength = 32
+
+// tagNames returns the names of the given tags as a comma separated list
+func tagNames(tags []models.Tag) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// priceagentTagCallback returns the encoded callback data for the buttons to assign or remove a tag of a price agent
+func priceagentTagCallback(action string, priceagentID, tagID int64) string {
+	return callback.New(action).With(callback.FieldID, priceagentID).With(FieldTagID, tagID).MustEncode()
+}
+
+// getChatTags returns the tags of a chat. Errors are logged, so that the tag menu can still be shown without the shortcuts.
+func getChatTags(chatID int64) []models.Tag {
+	tags, dbErr := database.GetTagsForChat(chatID)
+	if dbErr != nil {
+		log.Printf("getChatTags: %s\n", dbErr)
+	}
+
+	return tags
+}
+
+// priceagentTagsMessage generates the text and keyboard of the menu to manage the tags of a price agent.
+// Tags of the chat which are not yet assigned to the price agent are offered as shortcuts.
+func priceagentTagsMessage(priceagent models.PriceAgent, chatTags []models.Tag) (string, gotgbot.InlineKeyboardMarkup) {
+	assigned := make(map[int64]bool, len(priceagent.Tags))
+	for _, tag := range priceagent.Tags {
+		assigned[tag.ID] = true
+	}
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+
+	for _, tag := range priceagent.Tags {
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: fmt.Sprintf("❌ %s", tag.Name), CallbackData: priceagentTagCallback(RemovePriceagentTagState, priceagent.ID, tag.ID)},
+		})
+	}
+
+	for _, tag := range chatTags {
+		if assigned[tag.ID] {
+			continue
+		}
+
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+			{Text: fmt.Sprintf("➕ %s", tag.Name), CallbackData: priceagentTagCallback(AssignPriceagentTagState, priceagent.ID, tag.ID)},
+		})
+	}
+
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+		{Text: "🆕 Neuer Tag", CallbackData: menuCallbackWithID(AddPriceagentTagState, priceagent.ID)},
+		{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+	})
+
+	text := fmt.Sprintf("%s\n\nMit Tags kannst du deine Preisagenten in Ordnern zusammenfassen.\n\n", bold("Tags für "+html.EscapeString(priceagent.Name)))
+	if len(priceagent.Tags) == 0 {
+		text += "Der Preisagent hat noch keine Tags."
+	} else {
+		text += fmt.Sprintf("Aktuelle Tags: %s", bold(html.EscapeString(tagNames(priceagent.Tags))))
+	}
+
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// priceagentTagsHandler displays the menu to manage the tags of a price agent
+func priceagentTagsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("priceagentTagsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("priceagentTagsHandler: failed to answer callback query: %w", err)
+	}
+
+	text, markup := priceagentTagsMessage(priceagent, getChatTags(priceagent.ChatID))
+
+	_, _, err := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("priceagentTagsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// addPriceagentTagHandler asks the user for the name of a new tag for a price agent
+func addPriceagentTagHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("addPriceagentTagHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.TagPriceagent, ChatID: ctx.EffectiveChat.Id, Priceagent: priceagent}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("addPriceagentTagHandler: failed to answer callback query: %w", err)
+	}
+
+	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		{
+			{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(PriceagentTagsState, priceagent.ID)},
+		},
+	}}
+
+	editedText := fmt.Sprintf("Bitte sende mir den Namen des Tags für %s, z.B. 'PC-Build' oder 'Haushalt'!", bold(html.EscapeString(priceagent.Name)))
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("addPriceagentTagHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// textTagPriceagentHandler handles the text message containing the name of a new tag for a price agent
+func textTagPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	state := ctx.Data["state"].(userstate.UserState)
+
+	name, valid := parseName(ctx.EffectiveMessage.Text, maxTagNameLength)
+	if !valid {
+		_, _ = ctx.EffectiveMessage.Reply(bot, fmt.Sprintf("Bitte sende mir einen Namen mit maximal %d Zeichen!", maxTagNameLength), &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	priceagent, dbErr := database.GetPriceagentForChatByID(ctx.EffectiveChat.Id, state.Priceagent.ID)
+	if dbErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Der Preisagent existiert nicht mehr!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("textTagPriceagentHandler: %w", dbErr)
+	}
+
+	if _, tagErr := database.AddTagToPriceagent(priceagent, name); tagErr != nil {
+		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler beim Speichern des Tags aufgetreten!", &gotgbot.SendMessageOpts{})
+		return fmt.Errorf("textTagPriceagentHandler: %w", tagErr)
+	}
+
+	userstate.UserStates[ctx.EffectiveUser.Id] = userstate.UserState{State: userstate.Idle, ChatID: ctx.EffectiveChat.Id}
+
+	// Reload the price agent to display the new tag
+	priceagent, dbErr = database.GetPriceagentForChatByID(ctx.EffectiveChat.Id, state.Priceagent.ID)
+	if dbErr != nil {
+		return fmt.Errorf("textTagPriceagentHandler: %w", dbErr)
+	}
+
+	text, markup := priceagentTagsMessage(priceagent, getChatTags(priceagent.ChatID))
+
+	_, sendErr := bot.SendMessage(ctx.EffectiveChat.Id, text, &gotgbot.SendMessageOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if sendErr != nil {
+		return fmt.Errorf("textTagPriceagentHandler: failed to send message: %w", sendErr)
+	}
+
+	return nil
+}
+
+// changePriceagentTagHandler handles the callbacks for the buttons to assign an existing tag to a price agent
+// or to remove a tag from it.
+func changePriceagentTagHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("changePriceagentTagHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	tagID, parseErr := data.Int(FieldTagID)
+	if parseErr != nil {
+		return fmt.Errorf("changePriceagentTagHandler: failed to parse tag id: %w", newStaleButtonError(StaleInvalidData, parseErr))
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	tag, dbErr := database.GetTagForChatByID(ctx.EffectiveChat.Id, tagID)
+	if dbErr != nil {
+		return fmt.Errorf("changePriceagentTagHandler: %w", newStaleButtonError(StaleTagNotFound, dbErr))
+	}
+
+	if data.Action == RemovePriceagentTagState {
+		dbErr = database.RemoveTagFromPriceagent(priceagent, tag.ID)
+	} else {
+		_, dbErr = database.AddTagToPriceagent(priceagent, tag.Name)
+	}
+
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("changePriceagentTagHandler: %w", dbErr)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("changePriceagentTagHandler: failed to answer callback query: %w", err)
+	}
+
+	priceagent, dbErr = database.GetPriceagentForChatByID(ctx.EffectiveChat.Id, priceagent.ID)
+	if dbErr != nil {
+		return fmt.Errorf("changePriceagentTagHandler: %w", dbErr)
+	}
+
+	text, markup := priceagentTagsMessage(priceagent, getChatTags(priceagent.ChatID))
+
+	_, _, err := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("changePriceagentTagHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// showTagsHandler displays an overview of all the tags of a chat
+func showTagsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showTagsHandler: failed to answer callback query: %w", err)
+	}
+
+	tags, dbErr := database.GetTagsForChat(ctx.EffectiveChat.Id)
+	if dbErr != nil {
+		return fmt.Errorf("showTagsHandler: %w", dbErr)
+	}
+
+	var (
+		keyboard [][]gotgbot.InlineKeyboardButton
+		row      []gotgbot.InlineKeyboardButton
+	)
+
+	for _, tag := range tags {
+		row = append(row, gotgbot.InlineKeyboardButton{
+			Text:         fmt.Sprintf("🏷️ %s (%d)", tag.Name, len(tag.PriceAgents)),
+			CallbackData: menuCallbackWithID(ShowTagPriceagentsState, tag.ID),
+		})
+
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+		{Text: "↩️ Zurück", CallbackData: menuCallback(ViewPriceAgentState)},
+	})
+
+	text := "Das sind deine Tags:"
+	if len(tags) == 0 {
+		text = "Du hast noch keine Tags angelegt! Tags kannst du in den Einstellungen eines Preisagenten vergeben."
+	}
+
+	_, _, err := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}})
+	if err != nil {
+		return fmt.Errorf("showTagsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// tagPriceagentsMessage generates the text and keyboard of the menu listing the price agents of a tag
+func tagPriceagentsMessage(tag models.Tag) (string, gotgbot.InlineKeyboardMarkup) {
+	sortPriceagents(tag.PriceAgents, SortByName)
+
+	markup := generateEntityKeyboard(tag.PriceAgents, callback.New(ShowPriceagentDetailState), 2)
+	keyboard := markup.InlineKeyboard[:len(markup.InlineKeyboard)-1]
+	keyboard = append(keyboard,
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "⏸️ Alle pausieren", CallbackData: menuCallbackWithID(PauseTagPriceagentsState, tag.ID)},
+			{Text: "▶️ Alle fortsetzen", CallbackData: menuCallbackWithID(ResumeTagPriceagentsState, tag.ID)},
+		},
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "↩️ Zurück", CallbackData: menuCallback(ShowTagsState)},
+		},
+	)
+
+	paused := 0
+
+	for _, priceagent := range tag.PriceAgents {
+		if !priceagent.Enabled {
+			paused++
+		}
+	}
+
+	text := fmt.Sprintf("Preisagenten mit dem Tag %s:\n%d Preisagenten, davon %d pausiert", bold(html.EscapeString(tag.Name)), len(tag.PriceAgents), paused)
+
+	return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// showTagPriceagentsHandler displays the price agents of a tag
+func showTagPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, tag, parseErr := parseMenuTag(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("showTagPriceagentsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showTagPriceagentsHandler: failed to answer callback query: %w", err)
+	}
+
+	text, markup := tagPriceagentsMessage(tag)
+
+	_, _, err := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("showTagPriceagentsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// pauseTagPriceagentsHandler handles the callbacks for the buttons to pause or resume all the price agents of a tag
+func pauseTagPriceagentsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	data, tag, parseErr := parseMenuTag(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("pauseTagPriceagentsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
+	enabled := data.Action == ResumeTagPriceagentsState
+
+	count, dbErr := database.SetTagPriceagentsEnabled(ctx.EffectiveChat.Id, tag.ID, enabled)
+	if dbErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Es ist ein Fehler aufgetreten!", ShowAlert: true})
+		return fmt.Errorf("pauseTagPriceagentsHandler: %w", dbErr)
+	}
+
+	answerText := fmt.Sprintf("%d Preisagenten wurden pausiert!", count)
+	if enabled {
+		answerText = fmt.Sprintf("%d Preisagenten wurden fortgesetzt!", count)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: answerText}); err != nil {
+		return fmt.Errorf("pauseTagPriceagentsHandler: failed to answer callback query: %w", err)
+	}
+
+	for i := range tag.PriceAgents {
+		tag.PriceAgents[i].Enabled = enabled
+	}
+
+	text, markup := tagPriceagentsMessage(tag)
+
+	_, _, err := cbq.Message.EditText(bot, text, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
+	if err != nil {
+		return fmt.Errorf("pauseTagPriceagentsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// parseMenuTag decodes the callback data of the tag menus and loads the referenced tag of the current chat
+func parseMenuTag(ctx *ext.Context) (callback.Data, models.Tag, error) {
+	data, decodeErr := callback.FromContext(ctx)
+	if decodeErr != nil {
+		return callback.Data{}, models.Tag{}, newStaleButtonError(StaleInvalidData, decodeErr)
+	}
+
+	tagID, idErr := data.Int(callback.FieldID)
+	if idErr != nil {
+		return callback.Data{}, models.Tag{}, newStaleButtonError(StaleInvalidData, idErr)
+	}
+
+	tag, dbErr := database.GetTagForChatByID(ctx.EffectiveChat.Id, tagID)
+	if dbErr != nil {
+		return callback.Data{}, models.Tag{}, newStaleButtonError(StaleTagNotFound, dbErr)
+	}
+
+	return data, tag, nil
+}
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+)
+
+// keyboardCallbacks returns the text and callback data of all buttons of a keyboard
+func keyboardCallbacks(markup gotgbot.InlineKeyboardMarkup) map[string]string {
+	buttons := make(map[string]string)
+
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			buttons[button.Text] = button.CallbackData
+		}
+	}
+
+	return buttons
+}
+
+func Test_tagNames(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []models.Tag
+		want string
+	}{
+		{"No tags", nil, ""},
+		{"Single tag", []models.Tag{{Name: "PC"}}, "PC"},
+		{"Multiple tags", []models.Tag{{Name: "PC"}, {Name: "Audio"}}, "PC, Audio"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagNames(tt.tags); got != tt.want {
+				t.Errorf("tagNames() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_priceagentTagsMessage(t *testing.T) {
+	pc := models.Tag{ID: 1, Name: "PC"}
+	audio := models.Tag{ID: 2, Name: "Audio <3>"}
+
+	tests := []struct {
+		name         string
+		priceagent   models.PriceAgent
+		chatTags     []models.Tag
+		wantText     string
+		wantCallback map[string]string
+	}{
+		{
+			name:       "Without tags",
+			priceagent: models.PriceAgent{ID: 5, Name: "Maus"},
+			wantText:   "Der Preisagent hat noch keine Tags.",
+			wantCallback: map[string]string{
+				"🆕 Neuer Tag": menuCallbackWithID(AddPriceagentTagState, 5),
+				"↩️ Zurück":   menuCallbackWithID(ShowPriceagentDetailState, 5),
+			},
+		},
+		{
+			name:       "Assigned tags can be removed, others assigned",
+			priceagent: models.PriceAgent{ID: 5, Name: "Maus", Tags: []models.Tag{pc}},
+			chatTags:   []models.Tag{pc, audio},
+			wantText:   "Aktuelle Tags: <b>PC</b>",
+			wantCallback: map[string]string{
+				"❌ PC":        priceagentTagCallback(RemovePriceagentTagState, 5, 1),
+				"➕ Audio <3>": priceagentTagCallback(AssignPriceagentTagState, 5, 2),
+				"🆕 Neuer Tag": menuCallbackWithID(AddPriceagentTagState, 5),
+				"↩️ Zurück":   menuCallbackWithID(ShowPriceagentDetailState, 5),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, markup := priceagentTagsMessage(tt.priceagent, tt.chatTags)
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("priceagentTagsMessage() text = %q, want it to contain %q", text, tt.wantText)
+			}
+
+			if got := keyboardCallbacks(markup); !reflect.DeepEqual(got, tt.wantCallback) {
+				t.Errorf("priceagentTagsMessage() buttons = %v, want %v", got, tt.wantCallback)
+			}
+		})
+	}
+}
+
+func Test_priceagentTagCallback(t *testing.T) {
+	data, err := callback.Decode(priceagentTagCallback(RemovePriceagentTagState, 5, 7))
+	if err != nil {
+		t.Fatalf("failed to decode callback data: %s", err)
+	}
+
+	want := callback.New(RemovePriceagentTagState).With(callback.FieldID, 5).With(FieldTagID, 7)
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("priceagentTagCallback() = %v, want %v", data, want)
+	}
+}
+
+func Test_tagPriceagentsMessage(t *testing.T) {
+	tag := models.Tag{
+		ID:   3,
+		Name: "PC & Zubehör",
+		PriceAgents: []models.PriceAgent{
+			{ID: 2, Name: "Tastatur", Enabled: false},
+			{ID: 1, Name: "Maus", Enabled: true},
+		},
+	}
+
+	text, markup := tagPriceagentsMessage(tag)
+
+	wantText := "Preisagenten mit dem Tag <b>PC &amp; Zubehör</b>:\n2 Preisagenten, davon 1 pausiert"
+	if text != wantText {
+		t.Errorf("tagPriceagentsMessage() text = %q, want %q", text, wantText)
+	}
+
+	wantCallbacks := map[string]string{
+		"Maus":               menuCallbackWithID(ShowPriceagentDetailState, 1),
+		"⏸️ Tastatur":        menuCallbackWithID(ShowPriceagentDetailState, 2),
+		"⏸️ Alle pausieren":  menuCallbackWithID(PauseTagPriceagentsState, 3),
+		"▶️ Alle fortsetzen": menuCallbackWithID(ResumeTagPriceagentsState, 3),
+		"↩️ Zurück":          menuCallback(ShowTagsState),
+	}
+	if got := keyboardCallbacks(markup); !reflect.DeepEqual(got, wantCallbacks) {
+		t.Errorf("tagPriceagentsMessage() buttons = %v, want %v", got, wantCallbacks)
+	}
+
+	// The price agents are sorted by name
+	if first := markup.InlineKeyboard[0][0].Text; first != "Maus" {
+		t.Errorf("tagPriceagentsMessage() first price agent = %q, want %q", first, "Maus")
+	}
+}
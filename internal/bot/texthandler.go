@@ -24,7 +24,8 @@ func textHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		ok    bool
 	)
 
-	if state, ok = userstate.UserStates[userID]; !ok {
+	// States only apply to the chat they were entered in
+	if state, ok = userstate.UserStates[userID]; !ok || state.ChatID != ctx.EffectiveChat.Id {
 		state = userstate.UserState{
 			State:      userstate.Idle,
 			Priceagent: models.PriceAgent{},
@@ -38,15 +39,28 @@ func textHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return textNewPriceagentHandler(bot, ctx)
 	case userstate.SetNotification:
 		return textChangeNotificationSettingsHandler(bot, ctx)
+	case userstate.FilterPriceagents:
+		return textFilterPriceagentsHandler(bot, ctx)
+	case userstate.RenamePriceagent:
+		return textRenamePriceagentHandler(bot, ctx)
+	case userstate.TagPriceagent:
+		return textTagPriceagentHandler(bot, ctx)
+	case userstate.SearchProduct:
+		return textSearchProductHandler(bot, ctx)
+	}
+
+	// Barcodes sent in private chats are looked up on Geizhals
+	if code := normalizeProductCode(ctx.EffectiveMessage.Text); ctx.EffectiveChat.Type == gotgbot.ChatTypePrivate && geizhals.IsValidGTIN(code) {
+		return lookupProductCode(bot, ctx, code)
 	}
 
 	// Parse link and request price
-	return nil
+	return textEntityPreviewHandler(bot, ctx)
 }
 
 // textChangeNotificationSettingsHandler handles the text message when the user wants to change the notification settings of a price agent
 func textChangeNotificationSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
-	userID := ctx.EffectiveUser.Id
+	chatID := ctx.EffectiveChat.Id
 	var outOfRangePostfix string
 
 	price, parseErr := parsePrice(ctx.EffectiveMessage.Text)
@@ -68,7 +82,7 @@ func textChangeNotificationSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) e
 
 	state := ctx.Data["state"].(userstate.UserState)
 
-	dbErr := database.UpdateNotificationSettings(userID, state.Priceagent.ID, newNotifSettings)
+	dbErr := database.UpdateNotificationSettings(chatID, state.Priceagent.ID, newNotifSettings)
 	if dbErr != nil {
 		log.Printf("UpdateNotificationSettings: %s\n", dbErr)
 		_, _ = ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler beim Speichern der Einstellungen aufgetreten!", &gotgbot.SendMessageOpts{})
@@ -78,7 +92,7 @@ func textChangeNotificationSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) e
 
 	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 		{
-			{Text: "Zum Preisagenten!", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, state.Priceagent.ID)},
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, state.Priceagent.ID)},
 		},
 	}}
 
@@ -88,12 +102,30 @@ func textChangeNotificationSettingsHandler(bot *gotgbot.Bot, ctx *ext.Context) e
 	return nil
 }
 
-// textNewPriceagentHandler handles text messages that contain a link to a geizhals product or wishlist
+// textNewPriceagentHandler handles text messages that contain a link to a geizhals product or wishlist.
+// Messages containing multiple links are handled by bulkCreatePriceagents.
 func textNewPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 	log.Println("User in CreatePriceagent state!")
 	_, _ = bot.SendChatAction(ctx.EffectiveChat.Id, "typing", nil)
 
-	entity, downloadErr := geizhals.DownloadEntity(ctx.EffectiveMessage.Text)
+	urls := extractEntityURLs(ctx.EffectiveMessage)
+	if len(urls) == 0 {
+		// Instead of a URL, products can be looked up by their EAN
+		if code := normalizeProductCode(ctx.EffectiveMessage.Text); isGTINCandidate(code) {
+			return lookupProductCode(bot, ctx, code)
+		}
+
+		ctx.EffectiveMessage.Reply(bot, "Bitte sende eine valide Geizhals URL oder eine EAN!", &gotgbot.SendMessageOpts{})
+		return nil
+	}
+
+	if len(urls) > 1 {
+		return bulkCreatePriceagents(bot, ctx, urls)
+	}
+
+	entityURL := urls[0]
+
+	entity, downloadErr := geizhals.DownloadEntity(entityURL)
 	if downloadErr != nil {
 		log.Printf("textNewPriceagentHandler: %s\n", downloadErr)
 
@@ -106,41 +138,27 @@ func textNewPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return nil
 	}
 
-	// Check if the user already has a priceagent for this entity
-	hasPriceAgent, checkErr := database.HasUserPriceAgentForEntity(ctx.EffectiveUser.Id, entity.ID)
-	if checkErr != nil {
-		log.Printf("textNewPriceagentHandler: %s\n", checkErr)
-		ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten! Bitte probiere es später erneut!", &gotgbot.SendMessageOpts{})
-
-		return checkErr
-	}
-
-	if hasPriceAgent {
-		ctx.EffectiveMessage.Reply(bot, "Du hast bereits einen Preisagenten für dieses Produkt! Sende mir eine andere URL oder nutze /start, um zurück ins Menü zu gelangen.", &gotgbot.SendMessageOpts{})
-		return nil
-	}
-
-	location, parseErr := geizhals.LocationFromURL(ctx.EffectiveMessage.Text)
+	location, parseErr := geizhals.LocationFromURL(entityURL)
 	if parseErr != nil {
 		log.Printf("textNewPriceagentHandler: %s\n", parseErr)
 		ctx.EffectiveMessage.Reply(bot, "Bitte sende mir eine valide Geizhals URL!", &gotgbot.SendMessageOpts{})
 
 		return nil
 	}
-	newPriceagent := models.PriceAgent{
-		// ID:     entity.ID,
-		Name:   entity.Name,
-		UserID: ctx.EffectiveUser.Id,
-		Entity: entity,
-		NotificationSettings: models.NotificationSettings{
-			NotifyAlways: true,
-		},
-		Location: location,
-	}
 
-	createErr := database.CreatePriceAgentForUser(&newPriceagent)
+	newPriceagent, createErr := createPriceagent(ctx.EffectiveUser.Id, ctx.EffectiveChat.Id, entity, location)
 	if createErr != nil {
-		log.Printf("CreatePriceAgentForUser: %s\n", createErr)
+		log.Printf("textNewPriceagentHandler: %s\n", createErr)
+
+		switch {
+		case errors.Is(createErr, ErrPriceagentExists):
+			ctx.EffectiveMessage.Reply(bot, "Du hast bereits einen Preisagenten für dieses Produkt! Sende mir eine andere URL oder nutze /start, um zurück ins Menü zu gelangen.", &gotgbot.SendMessageOpts{})
+			return nil
+		case errors.Is(createErr, ErrMaxPriceagentsReached):
+			ctx.EffectiveMessage.Reply(bot, "Du hast bereits die maximale Anzahl an Preisagenten angelegt. Bitte lösche einen Preisagenten, bevor du einen neuen anlegst.", &gotgbot.SendMessageOpts{})
+			return nil
+		}
+
 		ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten!", &gotgbot.SendMessageOpts{})
 
 		return createErr
@@ -148,7 +166,7 @@ func textNewPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 
 	markup := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
 		{
-			{Text: "Zum Preisagenten!", CallbackData: fmt.Sprintf("%s_%d", ShowPriceagentDetailState, newPriceagent.ID)},
+			{Text: "Zum Preisagenten!", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, newPriceagent.ID)},
 		},
 	}}
 
@@ -7,7 +7,6 @@ import (
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/userstate"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
-	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
@@ -23,8 +22,12 @@ func setNotificationBelowHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return fmt.Errorf("setNotificationBelowHandler: failed to parse callback data: %w", parseErr)
 	}
 
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
 	userID := ctx.EffectiveUser.Id
-	userstate.UserStates[userID] = userstate.UserState{State: userstate.SetNotification, Priceagent: priceagent}
+	userstate.UserStates[userID] = userstate.UserState{State: userstate.SetNotification, ChatID: ctx.EffectiveChat.Id, Priceagent: priceagent}
 
 	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
 		return fmt.Errorf("setNotificationBelowHandler: failed to answer callback query: %w", err)
@@ -50,11 +53,15 @@ func setNotificationAlwaysHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return fmt.Errorf("setNotificationAlwaysHandler: failed to parse callback data: %w", parseErr)
 	}
 
+	if !checkManagePermission(bot, ctx) {
+		return nil
+	}
+
 	newNotifSettings := models.NotificationSettings{
 		NotifyAlways: true,
 	}
 
-	dbUpdateErr := database.UpdateNotificationSettings(ctx.EffectiveUser.Id, priceagent.ID, newNotifSettings)
+	dbUpdateErr := database.UpdateNotificationSettings(ctx.EffectiveChat.Id, priceagent.ID, newNotifSettings)
 	if dbUpdateErr != nil {
 		log.Printf("UpdateNotificationSettings: %s\n", dbUpdateErr)
 		ctx.EffectiveMessage.Reply(bot, "Es ist ein Fehler aufgetreten!", &gotgbot.SendMessageOpts{})
@@ -69,31 +76,9 @@ func setNotificationAlwaysHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
 		return fmt.Errorf("setNotificationAlwaysHandler: failed to answer callback query: %w", err)
 	}
 
-	var backCallbackData string
-	switch priceagent.Entity.Type {
-	case geizhals.Wishlist:
-		backCallbackData = ShowWishlistPriceagentsState
-	case geizhals.Product:
-		backCallbackData = ShowProductPriceagentsState
-	default:
-		backCallbackData = "invalidType"
-	}
+	priceagent.NotificationSettings = newNotifSettings
+	editedText, markup := priceagentDetailMessage(priceagent, priceagentListOptions{SortOrder: SortByName})
 
-	linkName := createLink(priceagent.EntityURL(), priceagent.Entity.Name)
-	price := priceagent.CurrentEntityPrice()
-	editedText := fmt.Sprintf("%s kostet aktuell %s", linkName, bold(price.String()))
-	markup := gotgbot.InlineKeyboardMarkup{
-		InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
-			{
-				{Text: "⏰ Benachrichtigung", CallbackData: fmt.Sprintf("%s_%d", ChangePriceagentSettingsState, priceagent.ID)},
-				{Text: "📊 Preisverlauf", CallbackData: fmt.Sprintf("%s_%d", ShowPriceHistoryState, priceagent.ID)},
-			},
-			{
-				{Text: "❌ Löschen", CallbackData: fmt.Sprintf("%s_%d", DeletePriceagentConfirmState, priceagent.ID)},
-				{Text: "↩️ Zurück", CallbackData: backCallbackData},
-			},
-		},
-	}
 	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML"})
 	if err != nil {
 		return fmt.Errorf("showPriceagent: failed to edit message text: %w", err)
@@ -3,16 +3,35 @@ package userstate
 import "github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
 
 type UserState struct {
-	State      State
+	State State
+	// ChatID is the chat in which the user entered the state
+	ChatID     int64
 	Priceagent models.PriceAgent
+	// ListMenu is the price agent list the user is filtering
+	ListMenu string
 }
 
 type State int
 
 const (
-	Idle             State = iota
-	CreatePriceagent State = iota
-	SetNotification  State = iota
+	Idle              State = iota
+	CreatePriceagent  State = iota
+	SetNotification   State = iota
+	FilterPriceagents State = iota
+	RenamePriceagent  State = iota
+	TagPriceagent     State = iota
+	SearchProduct     State = iota
 )
 
 var UserStates = map[int64]UserState{}
+
+// ListFilterKey identifies a price agent list of a user in a chat
+type ListFilterKey struct {
+	UserID int64
+	ChatID int64
+	// List is the menu of the price agent list
+	List string
+}
+
+// ListFilters holds the text filters users applied to their price agent lists. Each list of each chat has its own filter.
+var ListFilters = map[ListFilterKey]string{}
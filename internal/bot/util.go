@@ -5,15 +5,20 @@ import (
 	"html"
 	"strings"
 
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 )
 
+// maxMessageLength is the maximum length of a text message sent via Telegram
+const maxMessageLength = 4096
+
 // createLink generates a clickable html link given a display name and a url
 func createLink(url, name string) string {
 	name = strings.TrimSpace(name)
-	return fmt.Sprintf("<a href=\"%s\">%s</a>", url, html.EscapeString(name))
+	return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(url), html.EscapeString(name))
 }
 
 // createPrice formats a given float to a formatted pricetag string
@@ -27,17 +32,33 @@ func bold(text string) string {
 	return fmt.Sprintf("<b>%s</b>", text)
 }
 
-// generateEntityKeyboard generates a gotgbot keyboard from a given list of entities
-func generateEntityKeyboard(priceagents []models.PriceAgent, menuID string, numColumns int) gotgbot.InlineKeyboardMarkup {
+// menuCallback returns the encoded callback data for a menu without any parameters
+func menuCallback(action string) string {
+	return callback.New(action).MustEncode()
+}
+
+// menuCallbackWithID returns the encoded callback data for a menu of a price agent, entity or tag
+func menuCallbackWithID(action string, id int64) string {
+	return callback.New(action).With(callback.FieldID, id).MustEncode()
+}
+
+// generateEntityKeyboard generates a gotgbot keyboard from a given list of entities. The buttons carry the given
+// callback data together with the ID of their price agent.
+func generateEntityKeyboard(priceagents []models.PriceAgent, detailData callback.Data, numColumns int) gotgbot.InlineKeyboardMarkup {
 	var keyboard [][]gotgbot.InlineKeyboardButton
 
 	var row []gotgbot.InlineKeyboardButton //nolint:prealloc
 	colCounter := 0
 
 	for _, priceagent := range priceagents {
+		buttonText := priceagent.Name
+		if !priceagent.Enabled {
+			buttonText = fmt.Sprintf("⏸️ %s", buttonText)
+		}
+
 		row = append(row, gotgbot.InlineKeyboardButton{
-			Text:         priceagent.Name,
-			CallbackData: fmt.Sprintf("%s_%d", menuID, priceagent.ID),
+			Text:         buttonText,
+			CallbackData: detailData.With(callback.FieldID, priceagent.ID).MustEncode(),
 		})
 		colCounter++
 
@@ -54,23 +75,25 @@ func generateEntityKeyboard(priceagents []models.PriceAgent, menuID string, numC
 	if len(priceagents) == 0 {
 		keyboard = [][]gotgbot.InlineKeyboardButton{
 			{
-				{Text: "🆕 Neuer Preisagent", CallbackData: NewPriceAgentState},
-				{Text: "↩️ Zurück", CallbackData: ViewPriceAgentState},
+				{Text: "🆕 Neuer Preisagent", CallbackData: menuCallback(NewPriceAgentState)},
+				{Text: "↩️ Zurück", CallbackData: menuCallback(ViewPriceAgentState)},
 			},
 		}
 	} else {
 		// Add back button at the bottom row
 		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
-			{Text: "🆕 Neuer Preisagent", CallbackData: NewPriceAgentState},
-			{Text: "↩️ Zurück", CallbackData: ViewPriceAgentState},
+			{Text: "🆕 Neuer Preisagent", CallbackData: menuCallback(NewPriceAgentState)},
+			{Text: "↩️ Zurück", CallbackData: menuCallback(ViewPriceAgentState)},
 		})
 	}
 
 	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
 }
 
+// allowedLocations are the locations of the Geizhals domains supported by the bot
+var allowedLocations = []string{"de", "at", "eu", "uk", "pl"}
+
 func isAllowedLocation(location string) (allowed bool) {
-	allowedLocations := []string{"de", "at", "uk", "pl"}
 	for _, allowedLocation := range allowedLocations {
 		if location == allowedLocation {
 			return true
@@ -79,3 +102,21 @@ func isAllowedLocation(location string) (allowed bool) {
 
 	return false
 }
+
+// extractEntityURLs returns all Geizhals URLs of a message. This includes URLs in the text or caption
+// as well as hidden URLs of text links.
+func extractEntityURLs(msg *gotgbot.Message) []string {
+	if msg == nil {
+		return nil
+	}
+
+	texts := []string{msg.Text, msg.Caption}
+
+	for _, entity := range append(msg.Entities, msg.CaptionEntities...) {
+		if entity.Type == "text_link" {
+			texts = append(texts, entity.Url)
+		}
+	}
+
+	return geizhals.FindEntityURLs(strings.Join(texts, "\n"))
+}
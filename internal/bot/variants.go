@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+// maxVariantsShown is the maximum number of variants listed in the variants menu
+const maxVariantsShown = 20
+
+// variantsMessage generates the text and keyboard of the menu listing the variants of a product. Every variant can be
+// turned into a product price agent, the whole family can be tracked by a single price agent for the cheapest variant.
+func variantsMessage(priceagent models.PriceAgent, variants []geizhals.SearchResult) (string, gotgbot.InlineKeyboardMarkup) {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Varianten von %s:\n", createLink(priceagent.EntityURL(), priceagent.Name)))
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+
+	for i, variant := range variants {
+		if i >= maxVariantsShown {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere Varianten", len(variants)-maxVariantsShown))
+			break
+		}
+
+		price := "kein Preis"
+		if variant.Price.Price > 0 {
+			price = createPrice(variant.Price.Price, variant.Price.Currency.String())
+		}
+
+		sb.WriteString(fmt.Sprintf("\n• %s: %s", createLink(variant.FullURL(priceagent.Location), variant.Name), bold(price)))
+
+		if variant.EntityID == priceagent.EntityID {
+			continue
+		}
+
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("🆕 %s", shortenName(variant.Name, maxItemButtonNameLength)),
+			CallbackData: entityCallback(SearchCreatePriceagentState, variant.EntityID, priceagent.Location),
+		}})
+	}
+
+	sb.WriteString("\n\nMit einem Preisagenten für die günstigste Variante wirst du benachrichtigt, sobald eine beliebige Variante günstiger wird.")
+
+	keyboard = append(keyboard,
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "👀 Günstigste Variante überwachen", CallbackData: menuCallbackWithID(TrackCheapestVariantState, priceagent.ID)},
+		},
+		[]gotgbot.InlineKeyboardButton{
+			{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+		},
+	)
+
+	return sb.String(), gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// loadVariants downloads the variants of the product of the given price agent.
+// Products without any other variant result in an alert to the user and an empty list.
+func loadVariants(bot *gotgbot.Bot, ctx *ext.Context, priceagent models.PriceAgent) ([]geizhals.SearchResult, error) {
+	cbq := ctx.Update.CallbackQuery
+
+	if priceagent.Entity.Type != geizhals.Product {
+		return nil, newStaleButtonError(StaleInvalidData, fmt.Errorf("price agent %d is not a product", priceagent.ID))
+	}
+
+	variants, downloadErr := geizhals.DownloadVariants(priceagent.Entity, priceagent.Location)
+	if downloadErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Die Varianten konnten nicht geladen werden!", ShowAlert: true})
+		return nil, fmt.Errorf("loadVariants: %w", downloadErr)
+	}
+
+	if len(variants) < 2 {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Für dieses Produkt gibt es keine Varianten!", ShowAlert: true})
+		return nil, nil
+	}
+
+	return variants, nil
+}
+
+// showVariantsHandler handles the callback for the variants button of a product price agent.
+func showVariantsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("showVariantsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	variants, loadErr := loadVariants(bot, ctx, priceagent)
+	if loadErr != nil || len(variants) == 0 {
+		return loadErr
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showVariantsHandler: failed to answer callback query: %w", err)
+	}
+
+	editedText, markup := variantsMessage(priceagent, variants)
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if err != nil {
+		return fmt.Errorf("showVariantsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// trackCheapestVariantHandler handles the callback for the button to track the cheapest variant of a product.
+// The variants are tracked via the Geizhals comparison page of all variants.
+func trackCheapestVariantHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("trackCheapestVariantHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	variants, loadErr := loadVariants(bot, ctx, priceagent)
+	if loadErr != nil || len(variants) == 0 {
+		return loadErr
+	}
+
+	entityIDs := make([]int64, 0, len(variants))
+	for _, variant := range variants {
+		entityIDs = append(entityIDs, variant.EntityID)
+	}
+
+	return createPriceagentFromButton(bot, ctx, geizhals.ComparisonURL(entityIDs, priceagent.Location), priceagent.Location)
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/callback"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/database"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+)
+
+const (
+	// maxWishlistItemsShown is the maximum number of items listed in the wishlist contents menu to stay below the message size limit
+	maxWishlistItemsShown = 30
+	// maxItemButtonNameLength is the maximum number of characters of an item name on a button
+	maxItemButtonNameLength = 30
+)
+
+// loadWishlistItems returns the stored items of the wishlist of a price agent. Items of wishlists which were
+// never checked since the contents are tracked are downloaded and stored.
+func loadWishlistItems(priceagent models.PriceAgent) ([]geizhals.WishlistItem, error) {
+	items, dbErr := database.GetWishlistItems(priceagent.EntityID, priceagent.Location)
+	if dbErr != nil {
+		return nil, fmt.Errorf("loadWishlistItems: %w", dbErr)
+	}
+
+	if len(items) > 0 {
+		return items, nil
+	}
+
+	entity, downloadErr := geizhals.UpdateEntity(priceagent.Entity, priceagent.Location)
+	if downloadErr != nil {
+		return nil, fmt.Errorf("loadWishlistItems: %w", downloadErr)
+	}
+
+	items = entity.GetItems(priceagent.Location)
+	if replaceErr := database.ReplaceWishlistItems(priceagent.EntityID, priceagent.Location, items); replaceErr != nil {
+		log.Println("Error storing wishlist items:", replaceErr)
+	}
+
+	return items, nil
+}
+
+// shortenName shortens a name to the given number of characters
+func shortenName(name string, maxLength int) string {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) <= maxLength {
+		return name
+	}
+
+	return string([]rune(name)[:maxLength-1]) + "…"
+}
+
+// wishlistItemsMessage generates the text and keyboard of the menu listing the contents of a wishlist or the
+// cheapest products of a category or the members of a product family. Every item can be turned into a product price agent with a single tap.
+func wishlistItemsMessage(priceagent models.PriceAgent, items []geizhals.WishlistItem) (string, gotgbot.InlineKeyboardMarkup) {
+	currency := priceagent.GetCurrency().String()
+	isProductList := priceagent.Entity.Type == geizhals.Category || priceagent.Entity.Type == geizhals.ProductFamily
+
+	var sb strings.Builder
+	switch priceagent.Entity.Type {
+	case geizhals.Category:
+		sb.WriteString(fmt.Sprintf("Die günstigsten Produkte in %s:\n", createLink(priceagent.EntityURL(), priceagent.Name)))
+	case geizhals.ProductFamily:
+		sb.WriteString(fmt.Sprintf("Die Varianten von %s:\n", createLink(priceagent.EntityURL(), priceagent.Name)))
+	default:
+		sb.WriteString(fmt.Sprintf("Inhalt von %s:\n", createLink(priceagent.EntityURL(), priceagent.Name)))
+	}
+
+	if len(items) == 0 {
+		sb.WriteString("\nDie Wunschliste ist leer.")
+	}
+
+	var keyboard [][]gotgbot.InlineKeyboardButton
+
+	for i, item := range items {
+		if i >= maxWishlistItemsShown {
+			sb.WriteString(fmt.Sprintf("\n… und %d weitere Artikel", len(items)-maxWishlistItemsShown))
+			break
+		}
+
+		name := item.Name
+		if name == "" {
+			name = fmt.Sprintf("Artikel %d", item.EntityID)
+		}
+
+		if isProductList {
+			sb.WriteString(fmt.Sprintf("\n%d. %s\n     %s\n", i+1, createLink(item.FullURL(priceagent.Location), name), bold(createPrice(item.Price, currency))))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n%d× %s\n", item.Amount, createLink(item.FullURL(priceagent.Location), name)))
+			sb.WriteString(fmt.Sprintf("     je %s = %s\n", createPrice(item.Price, currency), bold(createPrice(item.Subtotal(), currency))))
+		}
+
+		keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{{
+			Text:         fmt.Sprintf("🆕 %s", shortenName(name, maxItemButtonNameLength)),
+			CallbackData: callback.New(CreateWishlistItemPriceagentState).With(callback.FieldID, priceagent.ID).With(FieldEntity, item.EntityID).MustEncode(),
+		}})
+	}
+
+	if !isProductList {
+		sb.WriteString(fmt.Sprintf("\nSumme: %s", bold(priceagent.CurrentEntityPrice().String())))
+	}
+
+	keyboard = append(keyboard, []gotgbot.InlineKeyboardButton{
+		{Text: "↩️ Zurück", CallbackData: menuCallbackWithID(ShowPriceagentDetailState, priceagent.ID)},
+	})
+
+	return sb.String(), gotgbot.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// showWishlistItemsHandler handles the callback for the contents button of a wishlist price agent, the top products
+// button of a category price agent and the variants button of a product family price agent.
+func showWishlistItemsHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	cbq := ctx.Update.CallbackQuery
+
+	_, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("showWishlistItemsHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	if priceagent.Entity.Type != geizhals.Wishlist && priceagent.Entity.Type != geizhals.Category && priceagent.Entity.Type != geizhals.ProductFamily {
+		return newStaleButtonError(StaleInvalidData, fmt.Errorf("price agent %d has no items", priceagent.ID))
+	}
+
+	items, loadErr := loadWishlistItems(priceagent)
+	if loadErr != nil {
+		_, _ = cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{Text: "Der Inhalt der Wunschliste konnte nicht geladen werden!", ShowAlert: true})
+		return fmt.Errorf("showWishlistItemsHandler: %w", loadErr)
+	}
+
+	if _, err := cbq.Answer(bot, &gotgbot.AnswerCallbackQueryOpts{}); err != nil {
+		return fmt.Errorf("showWishlistItemsHandler: failed to answer callback query: %w", err)
+	}
+
+	editedText, markup := wishlistItemsMessage(priceagent, items)
+
+	_, _, err := cbq.Message.EditText(bot, editedText, &gotgbot.EditMessageTextOpts{ReplyMarkup: markup, ParseMode: "HTML", LinkPreviewOptions: &gotgbot.LinkPreviewOptions{IsDisabled: true}})
+	if err != nil {
+		return fmt.Errorf("showWishlistItemsHandler: failed to edit message text: %w", err)
+	}
+
+	return nil
+}
+
+// createWishlistItemPriceagentHandler handles the buttons of the wishlist contents menu and creates a product
+// price agent for the selected item at the location of the wishlist price agent.
+func createWishlistItemPriceagentHandler(bot *gotgbot.Bot, ctx *ext.Context) error {
+	data, priceagent, parseErr := parseMenuPriceagent(ctx)
+	if parseErr != nil {
+		return fmt.Errorf("createWishlistItemPriceagentHandler: failed to parse callback data: %w", parseErr)
+	}
+
+	itemID, idErr := data.Int(FieldEntity)
+	if idErr != nil {
+		return newStaleButtonError(StaleInvalidData, idErr)
+	}
+
+	items, dbErr := database.GetWishlistItems(priceagent.EntityID, priceagent.Location)
+	if dbErr != nil {
+		return fmt.Errorf("createWishlistItemPriceagentHandler: %w", dbErr)
+	}
+
+	var item geizhals.WishlistItem
+
+	for _, wishlistItem := range items {
+		if wishlistItem.EntityID == itemID {
+			item = wishlistItem
+			break
+		}
+	}
+
+	if item.EntityID == 0 {
+		return newStaleButtonError(StaleEntityNotFound, fmt.Errorf("item %d of wishlist %d: %w", itemID, priceagent.EntityID, database.ErrNotFound))
+	}
+
+	return createPriceagentFromButton(bot, ctx, item.FullURL(priceagent.Location), priceagent.Location)
+}
@@ -17,6 +17,7 @@ type Config struct {
 	UpdateIntervalMinutes int    `yaml:"update_interval_minutes"`
 	HTTPMaxTries          int    `yaml:"http_max_tries"`
 	MaxPriceAgents        int64  `yaml:"max_price_agents"`
+	ChartCacheChatID      int64  `yaml:"chart_cache_chat_id"`
 	Webhook               struct {
 		Enabled     bool   `yaml:"enabled"`
 		ListenIP    string `yaml:"listen_ip"`
@@ -30,6 +31,32 @@ type Config struct {
 		Enabled       bool   `yaml:"enabled"`
 		ProxyListPath string `yaml:"proxy_list_path"`
 	} `yaml:"proxy"`
+	// PriceComparison configures the comparison of the prices of a product across locations
+	PriceComparison struct {
+		// MarginPercent is the minimum difference in percent for another location to be reported as cheaper.
+		// It's a pointer to distinguish a margin of 0 from a missing key.
+		MarginPercent *float64 `yaml:"margin_percent"`
+		// ExchangeRates maps currency codes (e.g. GBP) to the value of one unit in euros
+		ExchangeRates map[string]float64 `yaml:"exchange_rates"`
+	} `yaml:"price_comparison"`
+	// AdminIDs are the Telegram user IDs of the operators of the bot, who receive alerts
+	AdminIDs []int64 `yaml:"admin_ids"`
+	// ScraperHealth configures the alert about failing downloads or parsing of Geizhals pages
+	ScraperHealth struct {
+		// WindowMinutes is the duration of the rolling window the failure rate is calculated for
+		WindowMinutes int `yaml:"window_minutes"`
+		// MinScrapes is the minimum number of scrapes within the window before the failure rate is checked
+		MinScrapes int `yaml:"min_scrapes"`
+		// FailureThresholdPercent is the failure rate in percent from which the admins are alerted
+		FailureThresholdPercent float64 `yaml:"failure_threshold_percent"`
+	} `yaml:"scraper_health"`
+	// Maintenance configures the maintenance mode, in which users only get a maintenance message
+	Maintenance struct {
+		// Enabled starts the bot in maintenance mode
+		Enabled bool `yaml:"enabled"`
+		// Automatic enables the maintenance mode while the scraper health check fails
+		Automatic bool `yaml:"automatic"`
+	} `yaml:"maintenance"`
 	LogDirectory string `yaml:"log_directory"`
 	Prometheus   struct {
 		Enabled    bool   `yaml:"enabled"`
@@ -137,6 +164,28 @@ func validateConfig(config Config) bool {
 		}
 	}
 
+	if config.PriceComparison.MarginPercent != nil && *config.PriceComparison.MarginPercent < 0 {
+		log.Fatalln("Price comparison margin must not be negative")
+		return false
+	}
+
+	if config.ScraperHealth.WindowMinutes < 0 || config.ScraperHealth.MinScrapes < 0 {
+		log.Fatalln("Scraper health window and minimum scrapes must not be negative")
+		return false
+	}
+
+	if config.ScraperHealth.FailureThresholdPercent < 0 || config.ScraperHealth.FailureThresholdPercent > 100 {
+		log.Fatalln("Scraper health failure threshold must be between 0 and 100 percent")
+		return false
+	}
+
+	for currency, rate := range config.PriceComparison.ExchangeRates {
+		if rate <= 0 {
+			log.Fatalf("Exchange rate for %s must be positive\n", currency)
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -151,4 +200,17 @@ func setDefaults(config *Config) {
 	if config.HTTPMaxTries == 0 {
 		config.HTTPMaxTries = 3
 	}
+	if config.PriceComparison.MarginPercent == nil {
+		defaultMargin := 5.0
+		config.PriceComparison.MarginPercent = &defaultMargin
+	}
+	if config.ScraperHealth.WindowMinutes == 0 {
+		config.ScraperHealth.WindowMinutes = 60
+	}
+	if config.ScraperHealth.MinScrapes == 0 {
+		config.ScraperHealth.MinScrapes = 20
+	}
+	if config.ScraperHealth.FailureThresholdPercent == 0 {
+		config.ScraperHealth.FailureThresholdPercent = 25
+	}
 }
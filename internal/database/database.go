@@ -1,8 +1,11 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/bot/models"
 	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/geizhals"
@@ -15,6 +18,9 @@ import (
 
 var db *gorm.DB
 
+// ErrNotFound is returned when a requested record doesn't exist
+var ErrNotFound = gorm.ErrRecordNotFound
+
 func InitDB() {
 	var err error
 
@@ -27,28 +33,50 @@ func InitDB() {
 
 	// Migrate the schema
 	migrateError := db.AutoMigrate(&models.User{}, &models.NotificationSettings{}, &models.PriceAgent{},
-		&geizhals.Entity{}, &geizhals.EntityPrice{})
+		&geizhals.Entity{}, &geizhals.EntityPrice{}, &geizhals.WishlistItem{}, &models.Tag{}, &models.Broadcast{},
+		&models.Tier{}, &models.ChatQuota{})
 	if migrateError != nil {
 		log.Println("Couldn't migrate database!", migrateError.Error())
 		panic("failed to migrate database")
 	}
 
-	deleteError := DeleteDisabledPriceagents()
-	if deleteError != nil {
-		log.Println("Couldn't delete pending price agents!", deleteError.Error())
-	}
+	migratePriceagentChats()
 
 	log.Println("Database init complete")
 }
 
-func CreatePriceAgentForUser(priceAgent *models.PriceAgent) error {
+// migratePriceagentChats assigns price agents created before the introduction of group chats to the private chat of their user.
+func migratePriceagentChats() {
+	if db.Migrator().HasIndex(&models.PriceAgent{}, "user_entity_idx") {
+		if dropErr := db.Migrator().DropIndex(&models.PriceAgent{}, "user_entity_idx"); dropErr != nil {
+			log.Println("Couldn't drop index user_entity_idx!", dropErr.Error())
+		}
+	}
+
+	tx := db.Model(&models.PriceAgent{}).Where("chat_id IS NULL OR chat_id = 0").Update("chat_id", gorm.Expr("user_id"))
+	if tx.Error != nil {
+		log.Println("Couldn't migrate price agent chats!", tx.Error.Error())
+	}
+}
+
+func CreatePriceAgentForChat(priceAgent *models.PriceAgent) error {
 	log.Println("Add priceagent to database!")
 
 	if priceAgent.UserID == 0 {
 		return fmt.Errorf("UserID mustn't be 0")
 	}
 
-	tx := db.Create(priceAgent)
+	if priceAgent.ChatID == 0 {
+		return fmt.Errorf("ChatID mustn't be 0")
+	}
+
+	// The entity is stored separately to avoid creating duplicate prices via the association
+	if saveErr := SaveEntity(priceAgent.Entity); saveErr != nil {
+		return saveErr
+	}
+	priceAgent.EntityID = priceAgent.Entity.ID
+
+	tx := db.Omit("Entity").Create(priceAgent)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return tx.Error
@@ -57,9 +85,9 @@ func CreatePriceAgentForUser(priceAgent *models.PriceAgent) error {
 	return nil
 }
 
-func GetPriceAgentCountForUser(userID int64) int64 {
+func GetPriceAgentCountForChat(chatID int64) int64 {
 	var count int64
-	db.Model(&models.PriceAgent{}).Where("user_id = ?", userID).Count(&count)
+	db.Model(&models.PriceAgent{}).Where("chat_id = ?", chatID).Count(&count)
 
 	return count
 }
@@ -85,6 +113,30 @@ func GetPriceAgentWishlistCount() int64 {
 	return count
 }
 
+// GetPriceAgentCountByType returns the number of price agents for entities of the given type
+func GetPriceAgentCountByType(entityType geizhals.EntityType) int64 {
+	var count int64
+	db.Model(&models.PriceAgent{}).Joins("JOIN entities on price_agents.entity_id = entities.id").Where("entities.type = ?", entityType).Count(&count)
+
+	return count
+}
+
+// GetEnabledPriceAgentCount returns the number of price agents which aren't paused
+func GetEnabledPriceAgentCount() int64 {
+	var count int64
+	db.Model(&models.PriceAgent{}).Where("enabled = true").Count(&count)
+
+	return count
+}
+
+// GetPriceAgentCountForUser returns the number of price agents created by the given user in any chat
+func GetPriceAgentCountForUser(userID int64) int64 {
+	var count int64
+	db.Model(&models.PriceAgent{}).Where("user_id = ?", userID).Count(&count)
+
+	return count
+}
+
 func GetUserCount() int64 {
 	var count int64
 	db.Model(&models.User{}).Count(&count)
@@ -97,6 +149,18 @@ func CreateUser(user models.User) error {
 	return tx.Error
 }
 
+// GetUserByID returns the user with the given ID
+func GetUserByID(userID int64) (models.User, error) {
+	var user models.User
+
+	tx := db.Where("id = ?", userID).First(&user)
+	if tx.Error != nil {
+		return models.User{}, fmt.Errorf("GetUserByID: %w", tx.Error)
+	}
+
+	return user, nil
+}
+
 func GetDarkmode(userID int64) bool {
 	var user models.User
 
@@ -135,7 +199,7 @@ func GetActivePriceAgents() ([]models.PriceAgent, error) {
 	return priceagents, nil
 }
 
-func DeletePriceAgentForUser(priceAgent models.PriceAgent) error {
+func DeletePriceAgent(priceAgent models.PriceAgent) error {
 	log.Println("Delete priceagent!")
 
 	if priceAgent.UserID == 0 {
@@ -150,20 +214,55 @@ func DeletePriceAgentForUser(priceAgent models.PriceAgent) error {
 		return tx.Error
 	}
 
+	if err := db.Model(&priceAgent).Association("Tags").Clear(); err != nil {
+		log.Println(err)
+		return err
+	}
+
 	tx = db.Delete(&priceAgent)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return tx.Error
 	}
 
+	deleteUnusedTags(db, priceAgent.ChatID)
+
 	return nil
 }
 
-func GetProductPriceagentsForUser(userID int64) ([]models.PriceAgent, error) {
+// GetProductPriceagentsForChat returns the product price agents of a chat. Product families are listed along with
+// the products, as they track the cheapest of several products.
+func GetProductPriceagentsForChat(chatID int64) ([]models.PriceAgent, error) {
+	var priceagents []models.PriceAgent
+	query := &models.PriceAgent{ChatID: chatID}
+
+	tx := db.Preload("Entity").Preload("Entity.Prices").Joins("JOIN entities on price_agents.entity_id = entities.id").Where(query).Where("entities.type IN ?", []geizhals.EntityType{geizhals.Product, geizhals.ProductFamily}).Find(&priceagents)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return []models.PriceAgent{}, tx.Error
+	}
+
+	return priceagents, nil
+}
+
+func GetWishlistPriceagentsForChat(chatID int64) ([]models.PriceAgent, error) {
+	var priceagents []models.PriceAgent
+	query := &models.PriceAgent{ChatID: chatID}
+
+	tx := db.Preload("Entity").Preload("Entity.Prices").Joins("JOIN entities on price_agents.entity_id = entities.id").Where(query).Where("entities.type = ?", geizhals.Wishlist).Find(&priceagents)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return []models.PriceAgent{}, tx.Error
+	}
+
+	return priceagents, nil
+}
+
+func GetCategoryPriceagentsForChat(chatID int64) ([]models.PriceAgent, error) {
 	var priceagents []models.PriceAgent
-	query := &models.PriceAgent{UserID: userID, Enabled: true}
+	query := &models.PriceAgent{ChatID: chatID}
 
-	tx := db.Joins("JOIN entities on price_agents.entity_id = entities.id").Where(query).Where("entities.type = ?", geizhals.Product).Find(&priceagents)
+	tx := db.Preload("Entity").Preload("Entity.Prices").Joins("JOIN entities on price_agents.entity_id = entities.id").Where(query).Where("entities.type = ?", geizhals.Category).Find(&priceagents)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return []models.PriceAgent{}, tx.Error
@@ -172,11 +271,12 @@ func GetProductPriceagentsForUser(userID int64) ([]models.PriceAgent, error) {
 	return priceagents, nil
 }
 
-func GetWishlistPriceagentsForUser(userID int64) ([]models.PriceAgent, error) {
+// GetPriceagentsForChat returns all the priceagents of a chat including their entities and notification settings.
+// Paused priceagents are included.
+func GetPriceagentsForChat(chatID int64) ([]models.PriceAgent, error) {
 	var priceagents []models.PriceAgent
-	query := &models.PriceAgent{UserID: userID, Enabled: true}
 
-	tx := db.Joins("JOIN entities on price_agents.entity_id = entities.id").Where(query).Where("entities.type = ?", geizhals.Wishlist).Find(&priceagents)
+	tx := db.Preload("Entity").Preload("Entity.Prices").Preload("NotificationSettings").Where("chat_id = ?", chatID).Order("id").Find(&priceagents)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return []models.PriceAgent{}, tx.Error
@@ -185,9 +285,9 @@ func GetWishlistPriceagentsForUser(userID int64) ([]models.PriceAgent, error) {
 	return priceagents, nil
 }
 
-func GetPriceagentForUserByID(userID int64, priceagentID int64) (models.PriceAgent, error) {
+func GetPriceagentForChatByID(chatID int64, priceagentID int64) (models.PriceAgent, error) {
 	var priceagent models.PriceAgent
-	tx := db.Preload("Entity").Preload("Entity.Prices").Preload("NotificationSettings").Where("user_id = ?", userID).Where("id = ?", priceagentID).First(&priceagent)
+	tx := db.Preload("Entity").Preload("Entity.Prices").Preload("NotificationSettings").Preload("Tags").Where("chat_id = ?", chatID).Where("id = ?", priceagentID).First(&priceagent)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return models.PriceAgent{}, tx.Error
@@ -196,10 +296,10 @@ func GetPriceagentForUserByID(userID int64, priceagentID int64) (models.PriceAge
 	return priceagent, nil
 }
 
-func UpdateNotificationSettings(userID int64, priceagentID int64, notifSettings models.NotificationSettings) error {
+func UpdateNotificationSettings(chatID int64, priceagentID int64, notifSettings models.NotificationSettings) error {
 	var priceagent models.PriceAgent
 
-	tx := db.Preload("NotificationSettings").Where("user_id = ?", userID).Where("id = ?", priceagentID).First(&priceagent)
+	tx := db.Preload("NotificationSettings").Where("chat_id = ?", chatID).Where("id = ?", priceagentID).First(&priceagent)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return tx.Error
@@ -235,13 +335,48 @@ func UpdatePriceagent(priceagent models.PriceAgent) error {
 	return nil
 }
 
-func DeleteDisabledPriceagents() error {
-	tx := db.Where("enabled = 0").Delete(&models.PriceAgent{})
+// RenamePriceagent changes the name of a chat's priceagent
+func RenamePriceagent(chatID int64, priceagentID int64, name string) error {
+	tx := db.Model(&models.PriceAgent{}).Where("chat_id = ?", chatID).Where("id = ?", priceagentID).Update("name", name)
 	if tx.Error != nil {
 		log.Println(tx.Error)
 		return tx.Error
 	}
 
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetPriceagentEnabled pauses or resumes a chat's priceagent. Paused priceagents are kept, but not checked for price changes.
+func SetPriceagentEnabled(chatID int64, priceagentID int64, enabled bool) error {
+	tx := db.Model(&models.PriceAgent{}).Where("chat_id = ?", chatID).Where("id = ?", priceagentID).Update("enabled", enabled)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetCompareLocations sets the locations, whose prices are compared with the price of a chat's priceagent.
+func SetCompareLocations(chatID int64, priceagentID int64, locations []string) error {
+	tx := db.Model(&models.PriceAgent{}).Where("chat_id = ?", chatID).Where("id = ?", priceagentID).Update("compare_locations", strings.Join(locations, ","))
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
 	return nil
 }
 
@@ -273,10 +408,10 @@ func GetAllEntitiesWithPriceagents() ([]geizhals.Entity, error) {
 	return entities, nil
 }
 
-// HasUserPriceAgentForEntity checks if a user already has a priceagent for a given entity
-func HasUserPriceAgentForEntity(userID int64, entityID int64) (bool, error) {
+// HasChatPriceAgentForEntity checks if a chat already has a priceagent for a given entity
+func HasChatPriceAgentForEntity(chatID int64, entityID int64) (bool, error) {
 	var priceagent models.PriceAgent
-	query := &models.PriceAgent{UserID: userID, EntityID: entityID, Enabled: true}
+	query := &models.PriceAgent{ChatID: chatID, EntityID: entityID}
 
 	tx := db.Where(query).Limit(1).Find(&priceagent)
 	if tx.Error != nil {
@@ -302,6 +437,42 @@ func GetPriceAgentsForEntity(entityID int64) ([]models.PriceAgent, error) {
 	return priceagents, nil
 }
 
+// GetEntityByID returns the entity with the given ID including all of its prices
+func GetEntityByID(entityID int64) (geizhals.Entity, error) {
+	var entity geizhals.Entity
+
+	tx := db.Preload("Prices").Where("id = ?", entityID).First(&entity)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return geizhals.Entity{}, tx.Error
+	}
+
+	return entity, nil
+}
+
+// SaveEntity creates or updates the given entity and its prices in the database
+func SaveEntity(entity geizhals.Entity) error {
+	tx := db.Omit("Prices", "Items").Save(&entity)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return tx.Error
+	}
+
+	for _, price := range entity.Prices {
+		UpdateEntityPrice(price)
+	}
+
+	if entity.Type == geizhals.Wishlist || entity.Type == geizhals.Category || entity.Type == geizhals.ProductFamily {
+		for _, price := range entity.Prices {
+			if replaceErr := ReplaceWishlistItems(entity.ID, price.Location, entity.GetItems(price.Location)); replaceErr != nil {
+				return replaceErr
+			}
+		}
+	}
+
+	return nil
+}
+
 func UpdateEntity(entity geizhals.Entity) {
 	tx := db.Model(&geizhals.Entity{}).Where("id = ?", entity.ID).Updates(entity)
 	if tx.Error != nil {
@@ -310,6 +481,17 @@ func UpdateEntity(entity geizhals.Entity) {
 	}
 }
 
+// UpdateEntityURL updates the stored path of an entity, e.g. after Geizhals renamed the slug of a product.
+func UpdateEntityURL(entityID int64, path string) error {
+	tx := db.Model(&geizhals.Entity{}).Where("id = ?", entityID).Update("url", path)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return tx.Error
+	}
+
+	return nil
+}
+
 func UpdateEntityPrice(price geizhals.EntityPrice) {
 	tx := db.Model(&geizhals.EntityPrice{}).Where("entity_id = ?", price.EntityID).Where("location = ?", price.Location).Updates(price)
 	if tx.RowsAffected == 0 {
@@ -322,13 +504,91 @@ func UpdateEntityPrice(price geizhals.EntityPrice) {
 	}
 }
 
+// UpdateEntityStatus stores the status of an entity at a location along with the time of the change
+func UpdateEntityStatus(entityID int64, location string, status geizhals.EntityStatus) error {
+	now := time.Now()
+
+	tx := db.Model(&geizhals.EntityPrice{}).Where("entity_id = ?", entityID).Where("location = ?", location).Updates(map[string]any{"status": status, "status_since": now})
+	if tx.Error == nil && tx.RowsAffected == 0 {
+		tx = db.Create(&geizhals.EntityPrice{EntityID: entityID, Location: location, Currency: geizhals.CurrencyFromLocation(location), Status: status, StatusSince: now})
+	}
+
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return tx.Error
+	}
+
+	return nil
+}
+
+// GetGonePriceagentsForChat returns the priceagents of a chat whose entities have been delisted or without offers
+// at the location of the priceagent since before the given time.
+func GetGonePriceagentsForChat(chatID int64, before time.Time) ([]models.PriceAgent, error) {
+	var priceagents []models.PriceAgent
+
+	tx := db.Preload("Entity").Preload("Entity.Prices").
+		Joins("JOIN entity_prices on entity_prices.entity_id = price_agents.entity_id AND entity_prices.location = price_agents.location").
+		Where("price_agents.chat_id = ?", chatID).
+		Where("entity_prices.status IN ?", []geizhals.EntityStatus{geizhals.StatusNoOffers, geizhals.StatusDelisted}).
+		Where("entity_prices.status_since < ?", before).
+		Order("price_agents.id").
+		Find(&priceagents)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return []models.PriceAgent{}, tx.Error
+	}
+
+	return priceagents, nil
+}
+
+// GetWishlistItems returns the stored items of the given wishlist for the given location
+func GetWishlistItems(wishlistID int64, location string) ([]geizhals.WishlistItem, error) {
+	var items []geizhals.WishlistItem
+
+	tx := db.Where("wishlist_id = ?", wishlistID).Where("location = ?", location).Order("id").Find(&items)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return []geizhals.WishlistItem{}, tx.Error
+	}
+
+	return items, nil
+}
+
+// ReplaceWishlistItems replaces the stored items of the given wishlist for the given location with the given items
+func ReplaceWishlistItems(wishlistID int64, location string, items []geizhals.WishlistItem) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		deleteTx := tx.Where("wishlist_id = ?", wishlistID).Where("location = ?", location).Delete(&geizhals.WishlistItem{})
+		if deleteTx.Error != nil {
+			return fmt.Errorf("ReplaceWishlistItems: failed to delete items: %w", deleteTx.Error)
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		newItems := make([]geizhals.WishlistItem, 0, len(items))
+		for _, item := range items {
+			item.ID = 0
+			item.WishlistID = wishlistID
+			item.Location = location
+			newItems = append(newItems, item)
+		}
+
+		if createTx := tx.Create(&newItems); createTx.Error != nil {
+			return fmt.Errorf("ReplaceWishlistItems: failed to create items: %w", createTx.Error)
+		}
+
+		return nil
+	})
+}
+
 // DeleteUser deletes a user and their PriceAgents from the database
 func DeleteUser(userID int64) {
 	// Start a new transaction
 	_ = db.Transaction(func(tx *gorm.DB) error {
 		// delete all the things
 		var notifSettings []models.NotificationSettings
-		if err := tx.Model(&models.NotificationSettings{}).Joins("JOIN price_agents on price_agents.notification_id = notification_settings.id").Where("price_agents.user_id = ?", userID).Find(&notifSettings); err.Error != nil {
+		if err := tx.Model(&models.NotificationSettings{}).Joins("JOIN price_agents on price_agents.notification_id = notification_settings.id").Where("price_agents.chat_id = ?", userID).Find(&notifSettings); err.Error != nil {
 			// returning any error will roll back
 			return err.Error
 		}
@@ -340,7 +600,12 @@ func DeleteUser(userID int64) {
 			}
 		}
 
-		if err := tx.Model(&models.PriceAgent{}).Where("user_id = ?", userID).Delete(&models.PriceAgent{}); err.Error != nil {
+		if err := deleteTagsForChat(tx, userID); err != nil {
+			return err
+		}
+
+		// Only the private price agents are deleted, agents shared in groups stay with the group
+		if err := tx.Model(&models.PriceAgent{}).Where("chat_id = ?", userID).Delete(&models.PriceAgent{}); err.Error != nil {
 			// returning any error will roll back
 			return err.Error
 		}
@@ -353,3 +618,265 @@ func DeleteUser(userID int64) {
 		return nil
 	})
 }
+
+// DeletePriceagentsForChat deletes all the price agents of a chat, e.g. when the bot was removed from a group
+func DeletePriceagentsForChat(chatID int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var priceagents []models.PriceAgent
+		if err := tx.Where("chat_id = ?", chatID).Find(&priceagents); err.Error != nil {
+			return err.Error
+		}
+
+		for _, priceagent := range priceagents {
+			if err := tx.Delete(&models.NotificationSettings{}, priceagent.NotificationID); err.Error != nil {
+				return err.Error
+			}
+		}
+
+		if err := deleteTagsForChat(tx, chatID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("chat_id = ?", chatID).Delete(&models.PriceAgent{}); err.Error != nil {
+			return err.Error
+		}
+
+		return nil
+	})
+}
+
+// deleteTagsForChat deletes all the tags of a chat including their assignments to price agents
+func deleteTagsForChat(tx *gorm.DB, chatID int64) error {
+	if err := tx.Exec("DELETE FROM price_agent_tags WHERE tag_id IN (SELECT id FROM tags WHERE chat_id = ?)", chatID); err.Error != nil {
+		return err.Error
+	}
+
+	if err := tx.Where("chat_id = ?", chatID).Delete(&models.Tag{}); err.Error != nil {
+		return err.Error
+	}
+
+	return nil
+}
+
+// deleteUnusedTags deletes the tags of a chat which are no longer assigned to any price agent
+func deleteUnusedTags(tx *gorm.DB, chatID int64) {
+	err := tx.Where("chat_id = ?", chatID).Where("id NOT IN (SELECT tag_id FROM price_agent_tags)").Delete(&models.Tag{})
+	if err.Error != nil {
+		log.Println("Couldn't delete unused tags!", err.Error.Error())
+	}
+}
+
+// GetTagsForChat returns all the tags of a chat along with their price agents, ordered by name
+func GetTagsForChat(chatID int64) ([]models.Tag, error) {
+	var tags []models.Tag
+
+	tx := db.Preload("PriceAgents").Where("chat_id = ?", chatID).Order("name").Find(&tags)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return []models.Tag{}, tx.Error
+	}
+
+	return tags, nil
+}
+
+// GetTagForChatByID returns a tag of a chat including its price agents and their entities
+func GetTagForChatByID(chatID int64, tagID int64) (models.Tag, error) {
+	var tag models.Tag
+
+	tx := db.Preload("PriceAgents").Preload("PriceAgents.Entity").Preload("PriceAgents.Entity.Prices").Where("chat_id = ?", chatID).Where("id = ?", tagID).First(&tag)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return models.Tag{}, tx.Error
+	}
+
+	return tag, nil
+}
+
+// AddTagToPriceagent assigns the tag with the given name to a price agent. The tag is created if it doesn't exist yet.
+func AddTagToPriceagent(priceagent models.PriceAgent, tagName string) (models.Tag, error) {
+	tag := models.Tag{ChatID: priceagent.ChatID, Name: tagName}
+
+	tx := db.Where(&tag).FirstOrCreate(&tag)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return models.Tag{}, tx.Error
+	}
+
+	if err := db.Model(&priceagent).Omit("Tags.*").Association("Tags").Append(&tag); err != nil {
+		log.Println(err)
+		return models.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// RemoveTagFromPriceagent removes a tag from a price agent. Tags without any price agents are deleted.
+func RemoveTagFromPriceagent(priceagent models.PriceAgent, tagID int64) error {
+	tag := models.Tag{ID: tagID, ChatID: priceagent.ChatID}
+
+	if err := db.Model(&priceagent).Association("Tags").Delete(&tag); err != nil {
+		log.Println(err)
+		return err
+	}
+
+	deleteUnusedTags(db, priceagent.ChatID)
+
+	return nil
+}
+
+// SetTagPriceagentsEnabled pauses or resumes all the price agents of a chat with the given tag.
+// It returns the number of updated price agents.
+func SetTagPriceagentsEnabled(chatID int64, tagID int64, enabled bool) (int64, error) {
+	tx := db.Model(&models.PriceAgent{}).
+		Where("chat_id = ?", chatID).
+		Where("id IN (SELECT price_agent_id FROM price_agent_tags WHERE tag_id = ?)", tagID).
+		Update("enabled", enabled)
+	if tx.Error != nil {
+		log.Println(tx.Error)
+		return 0, tx.Error
+	}
+
+	return tx.RowsAffected, nil
+}
+
+// CreateBroadcast stores a new broadcast and sets its ID
+func CreateBroadcast(broadcast *models.Broadcast) error {
+	if tx := db.Create(broadcast); tx.Error != nil {
+		return fmt.Errorf("CreateBroadcast: %w", tx.Error)
+	}
+
+	return nil
+}
+
+// GetBroadcastByID returns the broadcast with the given ID
+func GetBroadcastByID(broadcastID int64) (models.Broadcast, error) {
+	var broadcast models.Broadcast
+
+	if tx := db.Where("id = ?", broadcastID).First(&broadcast); tx.Error != nil {
+		return models.Broadcast{}, fmt.Errorf("GetBroadcastByID: %w", tx.Error)
+	}
+
+	return broadcast, nil
+}
+
+// GetRunningBroadcasts returns the broadcasts which were started, but not finished, e.g. because the bot was restarted
+func GetRunningBroadcasts() ([]models.Broadcast, error) {
+	var broadcasts []models.Broadcast
+
+	if tx := db.Where("status = ?", models.BroadcastRunning).Order("id").Find(&broadcasts); tx.Error != nil {
+		return nil, fmt.Errorf("GetRunningBroadcasts: %w", tx.Error)
+	}
+
+	return broadcasts, nil
+}
+
+// UpdateBroadcast stores the status and the progress of a broadcast
+func UpdateBroadcast(broadcast models.Broadcast) error {
+	tx := db.Model(&models.Broadcast{}).Where("id = ?", broadcast.ID).Updates(map[string]any{
+		"status":       broadcast.Status,
+		"last_user_id": broadcast.LastUserID,
+		"sent":         broadcast.Sent,
+		"blocked":      broadcast.Blocked,
+		"failed":       broadcast.Failed,
+	})
+	if tx.Error != nil {
+		return fmt.Errorf("UpdateBroadcast: %w", tx.Error)
+	}
+
+	return nil
+}
+
+// TransitionBroadcastStatus changes the status of a broadcast only if it currently has the status from. It returns
+// whether the status was changed, so that concurrent requests can't change the status twice.
+func TransitionBroadcastStatus(broadcastID int64, from, to models.BroadcastStatus) (bool, error) {
+	tx := db.Model(&models.Broadcast{}).Where("id = ? AND status = ?", broadcastID, from).Update("status", to)
+	if tx.Error != nil {
+		return false, fmt.Errorf("TransitionBroadcastStatus: %w", tx.Error)
+	}
+
+	return tx.RowsAffected == 1, nil
+}
+
+// GetTiers returns all tiers ordered by their limit
+func GetTiers() ([]models.Tier, error) {
+	var tiers []models.Tier
+
+	if tx := db.Order("max_price_agents, name").Find(&tiers); tx.Error != nil {
+		return nil, fmt.Errorf("GetTiers: %w", tx.Error)
+	}
+
+	return tiers, nil
+}
+
+// GetTierByName returns the tier with the given name
+func GetTierByName(name string) (models.Tier, error) {
+	var tier models.Tier
+
+	if tx := db.Where("name = ?", name).First(&tier); tx.Error != nil {
+		return models.Tier{}, fmt.Errorf("GetTierByName: %w", tx.Error)
+	}
+
+	return tier, nil
+}
+
+// SaveTier creates the tier with the given name or updates its limit
+func SaveTier(name string, maxPriceAgents int64) error {
+	tier, getErr := GetTierByName(name)
+	if getErr != nil && !errors.Is(getErr, ErrNotFound) {
+		return fmt.Errorf("SaveTier: %w", getErr)
+	}
+
+	tier.Name = name
+	tier.MaxPriceAgents = maxPriceAgents
+
+	if tx := db.Save(&tier); tx.Error != nil {
+		return fmt.Errorf("SaveTier: %w", tx.Error)
+	}
+
+	return nil
+}
+
+// DeleteTier deletes the tier with the given name. Chats of the tier fall back to the default tier.
+func DeleteTier(name string) error {
+	tier, getErr := GetTierByName(name)
+	if getErr != nil {
+		return fmt.Errorf("DeleteTier: %w", getErr)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if updateTx := tx.Model(&models.ChatQuota{}).Where("tier_id = ?", tier.ID).Update("tier_id", nil); updateTx.Error != nil {
+			return fmt.Errorf("DeleteTier: failed to unassign tier: %w", updateTx.Error)
+		}
+
+		if deleteTx := tx.Delete(&tier); deleteTx.Error != nil {
+			return fmt.Errorf("DeleteTier: %w", deleteTx.Error)
+		}
+
+		return nil
+	})
+}
+
+// GetChatQuota returns the quota of a chat including its tier. Chats without a quota get an empty quota.
+func GetChatQuota(chatID int64) (models.ChatQuota, error) {
+	var quota models.ChatQuota
+
+	tx := db.Preload("Tier").Where("chat_id = ?", chatID).First(&quota)
+	if errors.Is(tx.Error, ErrNotFound) {
+		return models.ChatQuota{ChatID: chatID}, nil
+	}
+
+	if tx.Error != nil {
+		return models.ChatQuota{}, fmt.Errorf("GetChatQuota: %w", tx.Error)
+	}
+
+	return quota, nil
+}
+
+// SaveChatQuota creates or updates the tier and the individual limit of a chat
+func SaveChatQuota(quota models.ChatQuota) error {
+	if tx := db.Omit("Tier").Save(&quota); tx.Error != nil {
+		return fmt.Errorf("SaveChatQuota: %w", tx.Error)
+	}
+
+	return nil
+}
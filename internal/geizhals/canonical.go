@@ -0,0 +1,151 @@
+package geizhals
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// geizhalsHostPattern matches URLs pointing to any of the supported Geizhals domains
+var geizhalsHostPattern = regexp.MustCompile(`^https://(?:geizhals\.(?:de|at|eu)|cenowarka\.pl|skinflint\.co\.uk)(?:[/?]|$)`)
+
+// trackingParams are query parameters added by newsletters, social networks or ad campaigns. Parameters starting with
+// utm_ are removed as well.
+var trackingParams = []string{"fbclid", "gclid", "msclkid", "mc_cid", "mc_eid", "ref"}
+
+// isTrackingParam checks if the given query parameter is only used for tracking
+func isTrackingParam(param string) bool {
+	if strings.HasPrefix(param, "utm_") {
+		return true
+	}
+
+	for _, trackingParam := range trackingParams {
+		if param == trackingParam {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizeURL brings the given URL into the form used by Geizhals. The scheme is set to https, the host is lowercased
+// and stripped of "www.", fragments and tracking parameters are removed. The order of the remaining query parameters
+// is kept. URLs which can't be parsed are returned unchanged.
+func NormalizeURL(rawurl string) string {
+	rawurl = strings.TrimSpace(rawurl)
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+
+	parsedURL, parseErr := url.Parse(rawurl)
+	if parseErr != nil {
+		return rawurl
+	}
+
+	parsedURL.Scheme = "https"
+	parsedURL.Host = strings.TrimPrefix(strings.ToLower(parsedURL.Host), "www.")
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+
+	var params []string
+
+	for _, param := range strings.Split(parsedURL.RawQuery, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if param == "" || isTrackingParam(key) {
+			continue
+		}
+
+		params = append(params, param)
+	}
+
+	parsedURL.RawQuery = strings.Join(params, "&")
+	parsedURL.ForceQuery = false
+
+	return parsedURL.String()
+}
+
+// IsGeizhalsURL checks if the given URL points to one of the supported Geizhals domains, regardless of its path.
+func IsGeizhalsURL(rawurl string) bool {
+	return geizhalsHostPattern.MatchString(NormalizeURL(rawurl))
+}
+
+// parseLegacyProductURL parses the legacy short form of product URLs, e.g. https://geizhals.at/?a=2378831
+func parseLegacyProductURL(rawurl string) (EntityURL, error) {
+	matches := queryURLPattern.FindStringSubmatch(rawurl)
+	if len(matches) != 2 {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	query, parseErr := url.ParseQuery(matches[1])
+	if parseErr != nil {
+		return EntityURL{}, fmt.Errorf("parseLegacyProductURL: %w", parseErr)
+	}
+
+	entityID, convertErr := strconv.ParseInt(query.Get("a"), 10, 64)
+	if convertErr != nil || entityID <= 0 {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	location, locationErr := LocationFromURL(rawurl)
+	if locationErr != nil {
+		return EntityURL{}, locationErr
+	}
+
+	path := fmt.Sprintf("a%d.html", entityID)
+
+	return EntityURL{
+		SubmittedURL: rawurl,
+		CleanURL:     fmt.Sprintf("https://%s/%s", geizhalsDomains[location], path),
+		Path:         path,
+		Location:     location,
+		EntityID:     entityID,
+		Type:         Product,
+	}, nil
+}
+
+// canonicalPath returns the path of the canonical URL referenced by the downloaded page, e.g. the current slug of a
+// renamed product. The path of the given URL is returned if the page doesn't reference a canonical URL of the same entity.
+func canonicalPath(ghURL EntityURL, doc *goquery.Document) string {
+	href, hrefExists := doc.Find(`link[rel="canonical"]`).First().Attr("href")
+	if !hrefExists {
+		return ghURL.Path
+	}
+
+	canonicalURL, parseErr := parseGeizhalsURL(href)
+	if parseErr != nil || canonicalURL.EntityID != ghURL.EntityID || canonicalURL.Type != ghURL.Type {
+		return ghURL.Path
+	}
+
+	return canonicalURL.Path
+}
+
+// resolveURL determines the entity of a downloaded page whose URL didn't match any known URL shape, e.g. a short link.
+// The URL the request was redirected to takes precedence over the canonical URL referenced by the page.
+func resolveURL(rawurl string, doc *goquery.Document) (EntityURL, error) {
+	var candidates []string
+
+	if doc.Url != nil {
+		candidates = append(candidates, doc.Url.String())
+	}
+
+	if href, hrefExists := doc.Find(`link[rel="canonical"]`).First().Attr("href"); hrefExists {
+		candidates = append(candidates, href)
+	}
+
+	for _, candidate := range candidates {
+		ghURL, parseErr := parseGeizhalsURL(candidate)
+		if parseErr != nil {
+			continue
+		}
+
+		ghURL.SubmittedURL = rawurl
+
+		return ghURL, nil
+	}
+
+	return EntityURL{}, ErrInvalidURL
+}
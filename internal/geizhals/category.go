@@ -0,0 +1,128 @@
+package geizhals
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CategoryTopN is the number of cheapest products tracked for category listings
+const CategoryTopN = 5
+
+// derivedIDFlag marks the IDs of category listings and product families. Geizhals doesn't assign IDs to them, hence the
+// ID is derived from the canonical path. The flag keeps the IDs apart from product IDs, wishlists use negative IDs.
+const derivedIDFlag = int64(1) << 62
+
+var (
+	// queryURLPattern matches URLs which only consist of the domain and a query, e.g. category listings and comparisons
+	queryURLPattern     = regexp.MustCompile(`^(?:https?://)?(?:geizhals\.(?:de|at|eu)|cenowarka\.pl|skinflint\.co\.uk)/?\?([^#\s]+)$`)
+	categoryNamePattern = regexp.MustCompile(`^[0-9a-zA-Z_]+$`)
+)
+
+// ignoredCategoryParams are query parameters of category listings, which don't change the listed products.
+// The sort order is always replaced by sorting by price.
+var ignoredCategoryParams = []string{"pg"}
+
+var ErrNoCategoryProducts = errors.New("no products with a price found in category")
+
+// parseCategoryURL parses the URL of a (filtered) category listing, e.g. https://geizhals.de/?cat=gra16_512&xf=9816_03+05+16+-+RTX+4070
+// The listing is always sorted by price, so that the cheapest products are tracked.
+func parseCategoryURL(rawurl string) (EntityURL, error) {
+	matches := queryURLPattern.FindStringSubmatch(rawurl)
+	if len(matches) != 2 {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	query, parseErr := url.ParseQuery(matches[1])
+	if parseErr != nil {
+		return EntityURL{}, fmt.Errorf("parseCategoryURL: %w", parseErr)
+	}
+
+	// Wishlists use the cat parameter as well, but their names contain a dash
+	if !categoryNamePattern.MatchString(query.Get("cat")) {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	for _, param := range ignoredCategoryParams {
+		query.Del(param)
+	}
+
+	for param := range query {
+		if isTrackingParam(param) {
+			query.Del(param)
+		}
+	}
+
+	query.Set("sort", "p")
+
+	location, locationErr := LocationFromURL(rawurl)
+	if locationErr != nil {
+		return EntityURL{}, locationErr
+	}
+
+	path := "?" + query.Encode()
+
+	return EntityURL{
+		SubmittedURL: rawurl,
+		CleanURL:     fmt.Sprintf("https://%s/%s", geizhalsDomains[location], path),
+		Path:         path,
+		Location:     location,
+		EntityID:     derivedEntityID(path),
+		Type:         Category,
+	}, nil
+}
+
+// derivedEntityID derives the ID of a category listing or product family from its canonical path.
+func derivedEntityID(path string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(path))
+
+	return int64(hash.Sum64()>>2) | derivedIDFlag
+}
+
+// parseCategory parses the geizhals category listing and returns its name, the cheapest price and the cheapest products.
+func parseCategory(doc *goquery.Document) (string, Price, []WishlistItem, error) {
+	name := strings.TrimSpace(doc.Find("h1").First().Text())
+	if name == "" {
+		name = "Kategorie"
+	}
+
+	var (
+		items    []WishlistItem
+		cheapest Price
+	)
+
+	for _, result := range parseSearchResults(doc) {
+		if len(items) >= CategoryTopN {
+			break
+		}
+
+		if result.Price.Price <= 0 {
+			continue
+		}
+
+		items = append(items, WishlistItem{
+			EntityID: result.EntityID,
+			Name:     result.Name,
+			URL:      result.URL,
+			Amount:   1,
+			Price:    result.Price.Price,
+			Currency: result.Price.Currency,
+		})
+
+		if cheapest.Price == 0 || result.Price.Price < cheapest.Price {
+			cheapest = result.Price
+		}
+	}
+
+	if len(items) == 0 {
+		return "", Price{}, nil, ErrNoCategoryProducts
+	}
+
+	return name, cheapest, items, nil
+}
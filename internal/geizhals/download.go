@@ -16,11 +16,17 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+// priceHistoryURL is the URL of the price history API. The location is selected by the loc parameter of the request.
 const priceHistoryURL = "https://geizhals.de/api/gh0/price_history"
 
 // DownloadEntity retrieves the metadata (name, price) for a given entity hosted on Geizhals.
 func DownloadEntity(url string) (Entity, error) {
 	ghURL, parseErr := parseGeizhalsURL(url)
+	if parseErr != nil && IsGeizhalsURL(url) {
+		// Short links and other URLs of unknown shape are resolved by following their redirects
+		return downloadUnknownEntity(url)
+	}
+
 	if parseErr != nil {
 		log.Printf("Error while parsing URL: %s - %s\n", url, parseErr)
 		return Entity{}, parseErr
@@ -29,8 +35,42 @@ func DownloadEntity(url string) (Entity, error) {
 	return downloadEntity(ghURL)
 }
 
+// downloadUnknownEntity downloads the page of a Geizhals URL of unknown shape and determines the entity from the URL
+// the request was redirected to or the canonical URL of the page.
+func downloadUnknownEntity(url string) (Entity, error) {
+	doc, downloadErr := downloadDocument(NormalizeURL(url))
+	if downloadErr != nil {
+		return Entity{}, downloadErr
+	}
+
+	ghURL, resolveErr := resolveURL(url, doc)
+	if resolveErr != nil {
+		log.Printf("Error while resolving URL: %s - %s\n", url, resolveErr)
+		return Entity{}, resolveErr
+	}
+
+	entity, parseErr := parseEntity(ghURL, doc)
+	recordScrape(ghURL.Type, ghURL.Location, parseErr)
+
+	return entity, parseErr
+}
+
 // downloadEntity retrieves the metadata (name, price) for a given entity hosted on Geizhals.
 func downloadEntity(url EntityURL) (Entity, error) {
+	doc, downloadErr := downloadDocument(url.CleanURL)
+	if downloadErr != nil {
+		recordScrape(url.Type, url.Location, downloadErr)
+		return Entity{}, downloadErr
+	}
+
+	entity, parseErr := parseEntity(url, doc)
+	recordScrape(url.Type, url.Location, parseErr)
+
+	return entity, parseErr
+}
+
+// downloadDocument downloads and parses the HTML of the given URL. Requests answered with 429 Too Many Requests are retried.
+func downloadDocument(url string) (*goquery.Document, error) {
 	var (
 		doc         *goquery.Document
 		statusCode  int
@@ -42,7 +82,7 @@ func downloadEntity(url EntityURL) (Entity, error) {
 	// execute function downloadHTML() maximum 3 times to avoid 429 Too Many Requests
 	for tries := 0; tries < maxTries; tries++ {
 		// First we download the html content of the given URL
-		doc, statusCode, downloadErr = downloadHTML(url.CleanURL)
+		doc, statusCode, downloadErr = downloadHTML(url)
 		if downloadErr == nil {
 			break
 		}
@@ -52,14 +92,14 @@ func downloadEntity(url EntityURL) (Entity, error) {
 			continue
 		}
 
-		return Entity{}, downloadErr
+		return nil, downloadErr
 	}
 
 	if downloadErr != nil {
-		return Entity{}, downloadErr
+		return nil, downloadErr
 	}
 
-	return parseEntity(url, doc)
+	return doc, nil
 }
 
 // downloadHTML downloads the HTML content of the given URL and returns the document and the HTTP status code.
@@ -87,6 +127,9 @@ func downloadHTML(entityURL string) (*goquery.Document, int, error) {
 	if resp.StatusCode == http.StatusTooManyRequests {
 		prometheus.HTTPRequests429.Inc()
 	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, resp.StatusCode, fmt.Errorf("error for http request: %w", ErrEntityNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Received status code %d - returning...\n", resp.StatusCode)
 		return nil, resp.StatusCode, fmt.Errorf("error for http request")
@@ -98,6 +141,9 @@ func downloadHTML(entityURL string) (*goquery.Document, int, error) {
 		return nil, resp.StatusCode, fmt.Errorf("error while parsing body: %w", err)
 	}
 
+	// Keep the URL after following redirects, e.g. to resolve short links
+	doc.Url = resp.Request.URL
+
 	return doc, resp.StatusCode, nil
 }
 
@@ -110,6 +156,10 @@ func DownloadPriceHistory(entityIDs, amounts []int64, location string) (PriceHis
 	// execute function downloadHTML() maximum 3 times to avoid 429 Too Many Requests
 	for tries := 0; tries < maxTries; tries++ {
 		resp, downloadErr = downloadPriceHistory(entityIDs, amounts, location)
+		if downloadErr != nil {
+			log.Println(downloadErr)
+			return PriceHistory{}, fmt.Errorf("error while downloading content from Geizhals: %w", downloadErr)
+		}
 
 		if resp.StatusCode == http.StatusTooManyRequests {
 			log.Printf("Too many requests, trying again (%d/%d)!", tries+1, maxTries)
@@ -126,11 +176,6 @@ func DownloadPriceHistory(entityIDs, amounts []int64, location string) (PriceHis
 	// Cleanup when this function ends
 	defer resp.Body.Close()
 
-	if downloadErr != nil {
-		log.Println(downloadErr)
-		return PriceHistory{}, fmt.Errorf("error while downloading content from Geizhals: %w", downloadErr)
-	}
-
 	if resp.StatusCode == http.StatusTooManyRequests {
 		log.Printf("Too many requests, returning...\n")
 		return PriceHistory{}, ErrTooManyRetries
@@ -154,7 +199,10 @@ func downloadPriceHistory(entityIDs, amounts []int64, location string) (*http.Re
 		log.Println("Using proxy: ", proxyURL)
 	}
 
-	// Currently, this requests only supports geizhals.de (coming from loc = "de").
+	if _, ok := geizhalsDomains[location]; !ok {
+		return nil, fmt.Errorf("downloadPriceHistory: invalid location '%s'", location)
+	}
+
 	requestBody := priceHistoryRequest{
 		ID:        entityIDs,
 		Itemcount: amounts,
@@ -173,6 +221,7 @@ func downloadPriceHistory(entityIDs, amounts []int64, location string) (*http.Re
 	resp, downloadErr := httpClient.Post(priceHistoryURL, "application/json", bytes.NewBuffer(result))
 	if downloadErr != nil {
 		prometheus.HTTPErrors.Inc()
+		return nil, downloadErr
 	}
 	if resp.StatusCode == http.StatusTooManyRequests {
 		prometheus.HTTPRequests429.Inc()
@@ -9,10 +9,14 @@ type Entity struct {
 	ID         int64 `json:"id"`
 	GeizhalsID int64
 	UpdatedAt  time.Time
-	Prices     []EntityPrice `gorm:"foreignkey:EntityID;constraint:OnDelete:CASCADE;"`
-	Name       string        `json:"name"`
-	URL        string        `json:"url"`
-	Type       EntityType    `json:"type"`
+	Prices     []EntityPrice  `gorm:"foreignkey:EntityID;constraint:OnDelete:CASCADE;"`
+	Items      []WishlistItem `gorm:"foreignkey:WishlistID;constraint:OnDelete:CASCADE;"`
+	Name       string         `json:"name"`
+	URL        string         `json:"url"`
+	Type       EntityType     `json:"type"`
+	// EAN and MPN are the European Article Number and the manufacturer part number of products
+	EAN string `json:"ean" gorm:"index"`
+	MPN string `json:"mpn" gorm:"index"`
 }
 
 // FullURL returns the URL to download the HTML of the entity for the given location.
@@ -47,9 +51,43 @@ func (e Entity) GetPrice(location string) EntityPrice {
 	}
 }
 
+// GetItems returns the items of a wishlist, the cheapest products of a category or the members of a product family
+// for the given location.
+func (e Entity) GetItems(location string) []WishlistItem {
+	var items []WishlistItem
+
+	for _, item := range e.Items {
+		if item.Location == location {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
 type EntityType int
 
 const (
 	Product  EntityType = 1
 	Wishlist EntityType = 2
+	// Category is a (filtered) category listing, of which the cheapest products are tracked
+	Category EntityType = 3
+	// ProductFamily is a set of products, e.g. the variants of a product, of which the cheapest member is tracked
+	ProductFamily EntityType = 4
 )
+
+// String returns the name of the entity type as used in metrics.
+func (t EntityType) String() string {
+	switch t {
+	case Product:
+		return "product"
+	case Wishlist:
+		return "wishlist"
+	case Category:
+		return "category"
+	case ProductFamily:
+		return "family"
+	}
+
+	return fmt.Sprintf("%d", int(t))
+}
@@ -13,6 +13,9 @@ type EntityPrice struct {
 	UpdatedAt time.Time
 	Price     float64  `gorm:"not null;default:0"`
 	Currency  Currency `gorm:"not null;default:1"`
+	// Status is the availability of the entity at the location as of the last check, StatusSince is the time it changed
+	Status      EntityStatus `gorm:"not null;default:0"`
+	StatusSince time.Time
 }
 
 func (e EntityPrice) String() string {
@@ -41,6 +44,20 @@ func (c Currency) String() string {
 	return ""
 }
 
+// Code returns the ISO 4217 code of the currency.
+func (c Currency) Code() string {
+	switch c {
+	case EUR:
+		return "EUR"
+	case PLN:
+		return "PLN"
+	case GBP:
+		return "GBP"
+	}
+
+	return ""
+}
+
 // CurrencyFromLocation returns the currency of the given location.
 func CurrencyFromLocation(location string) Currency {
 	switch location {
@@ -48,6 +65,8 @@ func CurrencyFromLocation(location string) Currency {
 		return EUR
 	case "at":
 		return EUR
+	case "eu":
+		return EUR
 	case "pl":
 		return PLN
 	case "uk":
@@ -0,0 +1,41 @@
+package geizhals
+
+import (
+	"log"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+)
+
+// defaultExchangeRates holds the value of one unit of a currency in euros. The rates are only used to compare
+// prices across locations, hence rough static rates are good enough. They can be overridden in the config file.
+var defaultExchangeRates = map[Currency]float64{
+	EUR: 1,
+	PLN: 0.23,
+	GBP: 1.17,
+}
+
+// ToEUR converts the given price in the given currency to euros.
+func ToEUR(price float64, currency Currency) float64 {
+	return price * exchangeRate(currency)
+}
+
+// exchangeRate returns the value of one unit of the given currency in euros.
+// Rates configured in the config file take precedence over the default rates.
+func exchangeRate(currency Currency) float64 {
+	if currency == EUR {
+		return 1
+	}
+
+	conf, err := config.GetConfig()
+	if err != nil {
+		log.Println("Error while reading config file: ", err)
+	} else if rate, ok := conf.PriceComparison.ExchangeRates[currency.Code()]; ok {
+		return rate
+	}
+
+	if rate, ok := defaultExchangeRates[currency]; ok {
+		return rate
+	}
+
+	return 1
+}
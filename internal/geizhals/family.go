@@ -0,0 +1,248 @@
+package geizhals
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// defaultFamilyName is used for product families whose members don't share a common name
+const defaultFamilyName = "Produktvergleich"
+
+var ErrNoFamilyPrices = errors.New("no product with a price found in product family")
+
+// ComparisonPath returns the canonical path of the Geizhals comparison page of the given products.
+// The IDs are sorted and deduplicated, so that the same set of products always results in the same path.
+func ComparisonPath(entityIDs []int64) string {
+	ids := slices.Clone(entityIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	params := make([]string, 0, len(ids))
+	for _, id := range ids {
+		params = append(params, fmt.Sprintf("cmp=%d", id))
+	}
+
+	return "?" + strings.Join(params, "&")
+}
+
+// ComparisonURL returns the URL of the Geizhals comparison page of the given products for the given location.
+func ComparisonURL(entityIDs []int64, location string) string {
+	domain, ok := geizhalsDomains[location]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("https://%s/%s", domain, ComparisonPath(entityIDs))
+}
+
+// parseComparisonURL parses the URL of a Geizhals comparison page, e.g. https://geizhals.de/?cmp=2979505&cmp=2979510
+// The compared products are tracked as a product family, of which the cheapest member determines the price.
+func parseComparisonURL(rawurl string) (EntityURL, error) {
+	matches := queryURLPattern.FindStringSubmatch(rawurl)
+	if len(matches) != 2 {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	query, parseErr := url.ParseQuery(matches[1])
+	if parseErr != nil {
+		return EntityURL{}, fmt.Errorf("parseComparisonURL: %w", parseErr)
+	}
+
+	var entityIDs []int64
+
+	for _, cmp := range query["cmp"] {
+		entityID, convertErr := strconv.ParseInt(cmp, 10, 64)
+		if convertErr != nil || entityID <= 0 {
+			return EntityURL{}, ErrInvalidURL
+		}
+
+		entityIDs = append(entityIDs, entityID)
+	}
+
+	// A family needs at least two different members, otherwise it's just a product
+	path := ComparisonPath(entityIDs)
+	if strings.Count(path, "cmp=") < 2 {
+		return EntityURL{}, ErrInvalidURL
+	}
+
+	location, locationErr := LocationFromURL(rawurl)
+	if locationErr != nil {
+		return EntityURL{}, locationErr
+	}
+
+	return EntityURL{
+		SubmittedURL: rawurl,
+		CleanURL:     fmt.Sprintf("https://%s/%s", geizhalsDomains[location], path),
+		Path:         path,
+		Location:     location,
+		EntityID:     derivedEntityID(path),
+		Type:         ProductFamily,
+	}, nil
+}
+
+// parseComparison parses the geizhals comparison page and returns the name of the family, the price of the cheapest
+// member and the members with a price.
+func parseComparison(doc *goquery.Document) (string, Price, []WishlistItem, error) {
+	var (
+		items    []WishlistItem
+		names    []string
+		cheapest Price
+	)
+
+	seen := make(map[int64]bool)
+
+	doc.Find("div.compare__product").Each(func(i int, selection *goquery.Selection) {
+		nameLink := selection.Find("a.compare__product-name").First()
+
+		href, hrefExists := nameLink.Attr("href")
+		if !hrefExists {
+			return
+		}
+
+		matches := productPathPattern.FindStringSubmatch(href)
+		if len(matches) != 2 {
+			return
+		}
+
+		entityID, convertErr := strconv.ParseInt(matches[1], 10, 0)
+		if convertErr != nil || seen[entityID] {
+			return
+		}
+		seen[entityID] = true
+
+		name := strings.TrimSpace(nameLink.Text())
+		names = append(names, name)
+
+		priceString := strings.TrimSpace(selection.Find("span.gh_price").First().Text())
+		priceString = strings.TrimSpace(strings.TrimPrefix(priceString, "ab"))
+
+		price, priceErr := parsePrice(priceString)
+		if priceErr != nil || price.Price <= 0 {
+			return
+		}
+
+		items = append(items, WishlistItem{
+			EntityID: entityID,
+			Name:     name,
+			URL:      matches[0],
+			Amount:   1,
+			Price:    price.Price,
+			Currency: price.Currency,
+		})
+
+		if cheapest.Price == 0 || price.Price < cheapest.Price {
+			cheapest = price
+		}
+	})
+
+	if len(items) == 0 {
+		return "", Price{}, nil, ErrNoFamilyPrices
+	}
+
+	name := commonNamePrefix(names)
+	if name == "" {
+		name = defaultFamilyName
+	}
+
+	return name, cheapest, items, nil
+}
+
+// commonNamePrefix returns the words all the given names start with, e.g. "Apple iPhone 15" for
+// "Apple iPhone 15 128GB schwarz" and "Apple iPhone 15 256GB blau".
+func commonNamePrefix(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	prefix := strings.Fields(names[0])
+
+	for _, name := range names[1:] {
+		words := strings.Fields(name)
+
+		length := 0
+		for length < len(prefix) && length < len(words) && prefix[length] == words[length] {
+			length++
+		}
+
+		prefix = prefix[:length]
+	}
+
+	// Variant names are often separated from the product name by a comma or a dash
+	return strings.TrimRight(strings.Join(prefix, " "), ",-– ")
+}
+
+// DownloadVariants downloads the product page of the given product and returns its variants, e.g. other colours or
+// capacities. The product itself is part of the returned variants.
+func DownloadVariants(entity Entity, location string) ([]SearchResult, error) {
+	if entity.Type != Product {
+		return nil, fmt.Errorf("DownloadVariants: entity %d is not a product", entity.ID)
+	}
+
+	doc, downloadErr := downloadDocument(entity.FullURL(location))
+	if downloadErr != nil {
+		return nil, fmt.Errorf("DownloadVariants: %w", downloadErr)
+	}
+
+	variants := parseVariants(doc)
+
+	if !slices.ContainsFunc(variants, func(variant SearchResult) bool { return variant.EntityID == entity.ID }) {
+		variants = append([]SearchResult{{EntityID: entity.ID, Name: entity.Name, URL: entity.URL}}, variants...)
+	}
+
+	return variants, nil
+}
+
+// parseVariants parses the variants listed on the geizhals product page. Variants without a valid product link are
+// skipped, variants without a price are returned with a price of 0.
+func parseVariants(doc *goquery.Document) []SearchResult {
+	var variants []SearchResult
+
+	seen := make(map[int64]bool)
+
+	doc.Find("div.variant__variants a.variant__variant").Each(func(i int, selection *goquery.Selection) {
+		href, hrefExists := selection.Attr("href")
+		if !hrefExists {
+			return
+		}
+
+		matches := productPathPattern.FindStringSubmatch(href)
+		if len(matches) != 2 {
+			return
+		}
+
+		entityID, convertErr := strconv.ParseInt(matches[1], 10, 0)
+		if convertErr != nil || seen[entityID] {
+			return
+		}
+		seen[entityID] = true
+
+		name, titleExists := selection.Attr("title")
+		if !titleExists || strings.TrimSpace(name) == "" {
+			name = selection.Find(".variant__variant-name").Text()
+		}
+
+		variant := SearchResult{
+			EntityID: entityID,
+			Name:     strings.TrimSpace(name),
+			URL:      matches[0],
+		}
+
+		priceString := strings.TrimSpace(selection.Find("span.gh_price").First().Text())
+		priceString = strings.TrimSpace(strings.TrimPrefix(priceString, "ab"))
+
+		price, priceErr := parsePrice(priceString)
+		if priceErr == nil {
+			variant.Price = price
+		}
+
+		variants = append(variants, variant)
+	})
+
+	return variants
+}
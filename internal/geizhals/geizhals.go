@@ -6,13 +6,17 @@ import (
 )
 
 var (
-	wishlistURLPattern = regexp.MustCompile(`^((?:https?://)?(?:geizhals\.(?:de|at)|cenowarka\.pl|skinflint\.co\.uk)/?((?:\?cat=WL-|wishlists/)(\d+))).*$`)
-	productURLPattern  = regexp.MustCompile(`^((?:https?://)?(?:geizhals\.(?:de|at)|cenowarka\.pl|skinflint\.co\.uk)/([0-9a-zA-Z\-]*a(\d+).html))\??.*$`)
+	wishlistURLPattern = regexp.MustCompile(`^((?:https?://)?(?:geizhals\.(?:de|at|eu)|cenowarka\.pl|skinflint\.co\.uk)/?((?:\?cat=WL-|wishlists/)(\d+))).*$`)
+	productURLPattern  = regexp.MustCompile(`^((?:https?://)?(?:geizhals\.(?:de|at|eu)|cenowarka\.pl|skinflint\.co\.uk)/([0-9a-zA-Z\-]*a(\d+).html))\??.*$`)
+	// entityURLSearchPattern finds candidates for Geizhals URLs anywhere inside a longer text
+	entityURLSearchPattern = regexp.MustCompile(`(?:https?://)?(?:geizhals\.(?:de|at|eu)|cenowarka\.pl|skinflint\.co\.uk)/[^\s<>"]*`)
+	// productPathPattern extracts the product path and ID from links to products, e.g. on wishlist or search result pages
+	productPathPattern = regexp.MustCompile(`[0-9a-zA-Z\-]*a(\d+)\.html`)
 )
 
 var (
-	locationPattern       = regexp.MustCompile(`hloc=(de|at|uk|pl)`)
-	locationDomainPattern = regexp.MustCompile(`(?:geizhals\.(de|at)|cenowarka\.(pl)|skinflint\.co\.(uk))`)
+	locationPattern       = regexp.MustCompile(`hloc=(de|at|eu|uk|pl)`)
+	locationDomainPattern = regexp.MustCompile(`(?:geizhals\.(de|at|eu)|cenowarka\.(pl)|skinflint\.co\.(uk))`)
 	geizhalsDomains       = map[string]string{
 		"de": "geizhals.de",
 		"at": "geizhals.at",
@@ -24,10 +28,17 @@ var (
 
 var ErrTooManyRetries = errors.New("too many retries")
 var ErrInvalidURL = errors.New("invalid URL")
+var ErrNoPriceHistory = errors.New("no price history available for this entity type")
+
+// UpdateEntity downloads the given entity for the given location and returns the updated Entity.
+// For wishlists the returned entity also contains the items of the wishlist.
+func UpdateEntity(entity Entity, location string) (Entity, error) {
+	return DownloadEntity(entity.FullURL(location))
+}
 
 // UpdateEntityPrice returns an updated EntityPrice struct from a given input Entity
 func UpdateEntityPrice(entity Entity, location string) (EntityPrice, error) {
-	updatedEntity, downloadErr := DownloadEntity(entity.FullURL(location))
+	updatedEntity, downloadErr := UpdateEntity(entity, location)
 	if len(updatedEntity.Prices) > 0 {
 		return updatedEntity.Prices[0], downloadErr
 	}
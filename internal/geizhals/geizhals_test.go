@@ -1,8 +1,14 @@
 package geizhals
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 func Test_parseGeizhalsURL(t *testing.T) {
@@ -80,6 +86,150 @@ func Test_parseGeizhalsURL(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Product variant URL",
+			args: args{
+				rawurl:     "https://geizhals.de/apple-iphone-15-128gb-schwarz-a2979505.html?v=l&hloc=de#variants",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.de/apple-iphone-15-128gb-schwarz-a2979505.html?v=l&hloc=de#variants",
+				CleanURL:     "https://geizhals.de/apple-iphone-15-128gb-schwarz-a2979505.html",
+				Path:         "apple-iphone-15-128gb-schwarz-a2979505.html",
+				Location:     "de",
+				EntityID:     2979505,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Comparison URL",
+			args: args{
+				rawurl:     "https://geizhals.de/?cmp=2979510&cmp=2979505&active=1",
+				entityType: ProductFamily,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.de/?cmp=2979510&cmp=2979505&active=1",
+				CleanURL:     "https://geizhals.de/?cmp=2979505&cmp=2979510",
+				Path:         "?cmp=2979505&cmp=2979510",
+				Location:     "de",
+				EntityID:     derivedEntityID("?cmp=2979505&cmp=2979510"),
+				Type:         ProductFamily,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Product URL EU",
+			args: args{
+				rawurl:     "https://geizhals.eu/jabra-elite-85t-a2378831.html",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.eu/jabra-elite-85t-a2378831.html",
+				CleanURL:     "https://geizhals.eu/jabra-elite-85t-a2378831.html",
+				Path:         "jabra-elite-85t-a2378831.html",
+				Location:     "eu",
+				EntityID:     2378831,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Wishlist URL EU",
+			args: args{
+				rawurl:     "https://geizhals.eu/wishlists/1156092",
+				entityType: Wishlist,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.eu/wishlists/1156092",
+				CleanURL:     "https://geizhals.eu/wishlists/1156092",
+				Path:         "wishlists/1156092",
+				Location:     "eu",
+				EntityID:     -1156092,
+				Type:         Wishlist,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Product URL with www and http",
+			args: args{
+				rawurl:     "http://www.geizhals.de/jabra-elite-85t-a2378831.html",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "http://www.geizhals.de/jabra-elite-85t-a2378831.html",
+				CleanURL:     "https://geizhals.de/jabra-elite-85t-a2378831.html",
+				Path:         "jabra-elite-85t-a2378831.html",
+				Location:     "de",
+				EntityID:     2378831,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Product URL without scheme and uppercase host",
+			args: args{
+				rawurl:     "Geizhals.AT/jabra-elite-85t-a2378831.html?utm_source=newsletter",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "Geizhals.AT/jabra-elite-85t-a2378831.html?utm_source=newsletter",
+				CleanURL:     "https://geizhals.at/jabra-elite-85t-a2378831.html",
+				Path:         "jabra-elite-85t-a2378831.html",
+				Location:     "at",
+				EntityID:     2378831,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Product URL without slug",
+			args: args{
+				rawurl:     "https://skinflint.co.uk/a2378831.html",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://skinflint.co.uk/a2378831.html",
+				CleanURL:     "https://skinflint.co.uk/a2378831.html",
+				Path:         "a2378831.html",
+				Location:     "uk",
+				EntityID:     2378831,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Legacy product URL",
+			args: args{
+				rawurl:     "https://geizhals.at/?a=2378831&fbclid=abc",
+				entityType: Product,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.at/?a=2378831&fbclid=abc",
+				CleanURL:     "https://geizhals.at/a2378831.html",
+				Path:         "a2378831.html",
+				Location:     "at",
+				EntityID:     2378831,
+				Type:         Product,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Wishlist URL with tracking parameters",
+			args: args{
+				rawurl:     "https://www.geizhals.de/?utm_source=share&cat=WL-1156092#top",
+				entityType: Wishlist,
+			},
+			want: EntityURL{
+				SubmittedURL: "https://www.geizhals.de/?utm_source=share&cat=WL-1156092#top",
+				CleanURL:     "https://geizhals.de/?cat=WL-1156092",
+				Path:         "?cat=WL-1156092",
+				Location:     "de",
+				EntityID:     -1156092,
+				Type:         Wishlist,
+			},
+			wantErr: false,
+		},
 		{
 			name: "Product URL Multiple",
 			args: args{
@@ -166,3 +316,632 @@ func Test_parseGeizhalsURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_FindEntityURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "Plain URL",
+			text: "https://geizhals.de/jabra-elite-85t-a2378831.html",
+			want: []string{"https://geizhals.de/jabra-elite-85t-a2378831.html"},
+		},
+		{
+			name: "Shared link with extra text",
+			text: "Schau mal hier: https://geizhals.de/jabra-elite-85t-a2378831.html?hloc=at was meinst du?",
+			want: []string{"https://geizhals.de/jabra-elite-85t-a2378831.html?hloc=at"},
+		},
+		{
+			name: "Multiple URLs with duplicates",
+			text: "https://geizhals.at/?cat=WL-1156092\nskinflint.co.uk/jabra-elite-85t-a2378831.html\nhttps://geizhals.at/?cat=WL-1156092",
+			want: []string{"https://geizhals.at/?cat=WL-1156092", "skinflint.co.uk/jabra-elite-85t-a2378831.html"},
+		},
+		{
+			name: "Short link",
+			text: "Schau mal: https://geizhals.de/s/AbC123 und www.geizhals.de/s/AbC123#top",
+			want: []string{"https://geizhals.de/s/AbC123"},
+		},
+		{
+			name: "No Geizhals URL",
+			text: "Hallo https://example.com/a2378831.html",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindEntityURLs(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindEntityURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseWishlistItems(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []WishlistItem
+	}{
+		{
+			name: "Items with name, link and price",
+			html: `<div class="wishlist__item" data-id="2378831" data-count="2">
+				<a class="wishlist__item-name" href="https://geizhals.de/jabra-elite-85t-a2378831.html?hloc=de">Jabra Elite 85t</a>
+				<span class="gh_price">€ 99,90</span>
+			</div>
+			<div class="wishlist__item" data-id="123" data-count="1">
+				<a class="wishlist__item-name" href="/some-product-a123.html">Some Product</a>
+				<span class="gh_price">€ 5,00</span>
+			</div>`,
+			want: []WishlistItem{
+				{EntityID: 2378831, Amount: 2, Name: "Jabra Elite 85t", URL: "jabra-elite-85t-a2378831.html", Price: 99.90, Currency: EUR},
+				{EntityID: 123, Amount: 1, Name: "Some Product", URL: "some-product-a123.html", Price: 5, Currency: EUR},
+			},
+		},
+		{
+			name: "Item without price",
+			html: `<div class="wishlist__item" data-id="123" data-count="3"></div>`,
+			want: []WishlistItem{{EntityID: 123, Amount: 3}},
+		},
+		{
+			name: "Items without ID or count are skipped",
+			html: `<div class="wishlist__item" data-count="1"></div><div class="wishlist__item" data-id="123"></div><div class="wishlist__item" data-id="abc" data-count="1"></div>`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("failed to parse html: %v", err)
+			}
+
+			if got := parseWishlistItems(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWishlistItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DiffWishlistItems(t *testing.T) {
+	oldItems := []WishlistItem{
+		{EntityID: 1, Amount: 1, Price: 10},
+		{EntityID: 2, Amount: 2, Price: 20},
+		{EntityID: 3, Amount: 1, Price: 30},
+		{EntityID: 5, Amount: 1, Price: 50},
+	}
+	newItems := []WishlistItem{
+		{EntityID: 1, Amount: 1, Price: 10},
+		{EntityID: 2, Amount: 2, Price: 15},
+		{EntityID: 4, Amount: 1, Price: 49},
+		{EntityID: 5, Amount: 3, Price: 40},
+	}
+
+	tests := []struct {
+		name            string
+		oldItems        []WishlistItem
+		newItems        []WishlistItem
+		want            []WishlistItemChange
+		wantDifferences []float64
+	}{
+		{
+			name:     "Price and composition changes",
+			oldItems: oldItems,
+			newItems: newItems,
+			want: []WishlistItemChange{
+				{Type: ItemPriceChanged, Item: newItems[1], OldPrice: 20, OldAmount: 2},
+				{Type: ItemAdded, Item: newItems[2]},
+				{Type: ItemAmountChanged, Item: newItems[3], OldPrice: 50, OldAmount: 1},
+				{Type: ItemRemoved, Item: oldItems[2], OldPrice: 30, OldAmount: 1},
+			},
+			wantDifferences: []float64{-10, 49, 70, -30},
+		},
+		{
+			name:     "No changes",
+			oldItems: oldItems,
+			newItems: oldItems,
+			want:     nil,
+		},
+		{
+			name:     "First check",
+			oldItems: nil,
+			newItems: oldItems[:1],
+			want:     []WishlistItemChange{{Type: ItemAdded, Item: oldItems[0]}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffWishlistItems(tt.oldItems, tt.newItems)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffWishlistItems() = %v, want %v", got, tt.want)
+			}
+
+			for i, wantDifference := range tt.wantDifferences {
+				if diff := got[i].Difference(); diff != wantDifference {
+					t.Errorf("WishlistItemChange.Difference() = %v, want %v", diff, wantDifference)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseSearchResults(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []SearchResult
+	}{
+		{
+			name: "Results with and without price",
+			html: `<article class="listview__item">
+				<a class="listview__name-link" href="jabra-elite-85t-a2378831.html?fs=jabra">Jabra Elite 85t</a>
+				<span class="price">ab € 99,90</span>
+			</article>
+			<article class="listview__item">
+				<a class="listview__name-link" href="/some-product-a123.html">Some Product</a>
+			</article>`,
+			want: []SearchResult{
+				{EntityID: 2378831, Name: "Jabra Elite 85t", URL: "jabra-elite-85t-a2378831.html", Price: Price{Price: 99.90, Currency: EUR}},
+				{EntityID: 123, Name: "Some Product", URL: "some-product-a123.html"},
+			},
+		},
+		{
+			name: "Duplicates and invalid links are skipped",
+			html: `<article class="listview__item"><a class="listview__name-link" href="/?cat=WL-123">Wishlist</a></article>
+			<article class="listview__item"><a class="listview__name-link" href="product-a1.html">Product</a></article>
+			<article class="listview__item"><a class="listview__name-link" href="product-a1.html">Product</a></article>
+			<article class="listview__item"><span class="price">€ 1,00</span></article>`,
+			want: []SearchResult{{EntityID: 1, Name: "Product", URL: "product-a1.html"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("failed to parse html: %v", err)
+			}
+
+			if got := parseSearchResults(doc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearchResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SearchURL(t *testing.T) {
+	if got, want := SearchURL("rtx 4070 & co", "at"), "https://geizhals.at/?fs=rtx+4070+%26+co"; got != want {
+		t.Errorf("SearchURL() = %v, want %v", got, want)
+	}
+
+	if got := SearchURL("rtx", "xx"); got != "" {
+		t.Errorf("SearchURL() = %v, want empty string for unknown location", got)
+	}
+}
+
+func Test_IsValidGTIN(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "4006381333931", want: true},
+		{code: "73513537", want: true},
+		{code: "036000291452", want: true},
+		{code: "10012345678902", want: true},
+		{code: "4006381333932", want: false},
+		{code: "400638133393", want: false},
+		{code: "40063813339a1", want: false},
+		{code: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := IsValidGTIN(tt.code); got != tt.want {
+				t.Errorf("IsValidGTIN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseProductCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		wantEAN string
+		wantMPN string
+	}{
+		{
+			name:    "EAN and MPN",
+			html:    `<div class="variant__header__codes">Herst. Art. Nr.: 100-100000910WOF • EAN: 0730143314572, 0730143314589</div>`,
+			wantEAN: "0730143314572",
+			wantMPN: "100-100000910WOF",
+		},
+		{
+			name:    "MPN only",
+			html:    `<div class="variant__header"><h1>Product</h1>Herstellernummer: ABC123</div>`,
+			wantMPN: "ABC123",
+		},
+		{
+			name: "Invalid EAN",
+			html: `<div class="variant__header__codes">EAN: 4006381333932</div>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("failed to parse html: %v", err)
+			}
+
+			ean, mpn := parseProductCodes(doc)
+			if ean != tt.wantEAN || mpn != tt.wantMPN {
+				t.Errorf("parseProductCodes() = (%v, %v), want (%v, %v)", ean, mpn, tt.wantEAN, tt.wantMPN)
+			}
+		})
+	}
+}
+
+func Test_parseCategoryURL(t *testing.T) {
+	path := "?cat=gra16_512&sort=p&xf=9816_03+05+16+-+RTX+4070"
+
+	tests := []struct {
+		name    string
+		rawurl  string
+		want    EntityURL
+		wantErr bool
+	}{
+		{
+			name:   "Filtered category",
+			rawurl: "https://geizhals.de/?cat=gra16_512&xf=9816_03+05+16+-+RTX+4070",
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.de/?cat=gra16_512&xf=9816_03+05+16+-+RTX+4070",
+				CleanURL:     "https://geizhals.de/" + path,
+				Path:         path,
+				Location:     "de",
+				EntityID:     derivedEntityID(path),
+				Type:         Category,
+			},
+		},
+		{
+			name:   "Sort order, page and tracking parameters are ignored",
+			rawurl: "geizhals.at/?xf=9816_03+05+16+-+RTX+4070&cat=gra16_512&sort=-p&pg=2&utm_source=test&fbclid=abc",
+			want: EntityURL{
+				SubmittedURL: "geizhals.at/?xf=9816_03+05+16+-+RTX+4070&cat=gra16_512&sort=-p&pg=2&utm_source=test&fbclid=abc",
+				CleanURL:     "https://geizhals.at/" + path,
+				Path:         path,
+				Location:     "at",
+				EntityID:     derivedEntityID(path),
+				Type:         Category,
+			},
+		},
+		{
+			name:   "Shop country filter is kept",
+			rawurl: "https://geizhals.de/?cat=gra16_512&xf=9816_03+05+16+-+RTX+4070&hloc=de&pg=3",
+			want: EntityURL{
+				SubmittedURL: "https://geizhals.de/?cat=gra16_512&xf=9816_03+05+16+-+RTX+4070&hloc=de&pg=3",
+				CleanURL:     "https://geizhals.de/?cat=gra16_512&hloc=de&sort=p&xf=9816_03+05+16+-+RTX+4070",
+				Path:         "?cat=gra16_512&hloc=de&sort=p&xf=9816_03+05+16+-+RTX+4070",
+				Location:     "de",
+				EntityID:     derivedEntityID("?cat=gra16_512&hloc=de&sort=p&xf=9816_03+05+16+-+RTX+4070"),
+				Type:         Category,
+			},
+		},
+		{
+			name:    "Wishlist",
+			rawurl:  "https://geizhals.de/?cat=WL-1156092",
+			wantErr: true,
+		},
+		{
+			name:    "Other domain",
+			rawurl:  "https://example.com/?cat=gra16_512",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCategoryURL(tt.rawurl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCategoryURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCategoryURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if id := derivedEntityID(path); id <= 0 || id&derivedIDFlag == 0 {
+		t.Errorf("derivedEntityID() = %d, must be positive and flagged", id)
+	}
+}
+
+func Test_parseCategory(t *testing.T) {
+	html := `<h1>Grafikkarten</h1>
+		<article class="listview__item"><a class="listview__name-link" href="gpu-a3.html">GPU 3</a></article>
+		<article class="listview__item"><a class="listview__name-link" href="gpu-a1.html">GPU 1</a><span class="price">€ 499,00</span></article>
+		<article class="listview__item"><a class="listview__name-link" href="gpu-a2.html">GPU 2</a><span class="price">€ 529,00</span></article>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse html: %v", err)
+	}
+
+	name, price, items, parseErr := parseCategory(doc)
+	if parseErr != nil {
+		t.Fatalf("parseCategory() error = %v", parseErr)
+	}
+
+	wantItems := []WishlistItem{
+		{EntityID: 1, Name: "GPU 1", URL: "gpu-a1.html", Amount: 1, Price: 499, Currency: EUR},
+		{EntityID: 2, Name: "GPU 2", URL: "gpu-a2.html", Amount: 1, Price: 529, Currency: EUR},
+	}
+
+	if name != "Grafikkarten" || price != (Price{Price: 499, Currency: EUR}) || !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("parseCategory() = (%v, %v, %v), want (%v, %v, %v)", name, price, items, "Grafikkarten", 499, wantItems)
+	}
+
+	emptyDoc, _ := goquery.NewDocumentFromReader(strings.NewReader("<h1>Leer</h1>"))
+	if _, _, _, emptyErr := parseCategory(emptyDoc); !errors.Is(emptyErr, ErrNoCategoryProducts) {
+		t.Errorf("parseCategory() error = %v, want %v", emptyErr, ErrNoCategoryProducts)
+	}
+}
+
+func Test_parseComparisonURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawurl   string
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "Sorted and deduplicated", rawurl: "https://geizhals.at/?cmp=3&cmp=1&cmp=3", wantPath: "?cmp=1&cmp=3"},
+		{name: "Single product", rawurl: "https://geizhals.de/?cmp=1&cmp=1", wantErr: true},
+		{name: "Invalid ID", rawurl: "https://geizhals.de/?cmp=1&cmp=abc", wantErr: true},
+		{name: "Category", rawurl: "https://geizhals.de/?cat=gra16_512", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseComparisonURL(tt.rawurl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseComparisonURL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && (got.Path != tt.wantPath || got.EntityID != derivedEntityID(tt.wantPath)) {
+				t.Errorf("parseComparisonURL() = %v, want path %v", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_parseComparison(t *testing.T) {
+	html := `<div class="compare__product"><a class="compare__product-name" href="apple-iphone-15-128gb-schwarz-a1.html">Apple iPhone 15 128GB schwarz</a><span class="gh_price">€ 799,00</span></div>
+		<div class="compare__product"><a class="compare__product-name" href="apple-iphone-15-256gb-blau-a2.html">Apple iPhone 15 256GB blau</a><span class="gh_price">ab € 749,00</span></div>
+		<div class="compare__product"><a class="compare__product-name" href="apple-iphone-15-512gb-rosa-a3.html">Apple iPhone 15 512GB rosa</a></div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse html: %v", err)
+	}
+
+	name, price, items, parseErr := parseComparison(doc)
+	if parseErr != nil {
+		t.Fatalf("parseComparison() error = %v", parseErr)
+	}
+
+	wantItems := []WishlistItem{
+		{EntityID: 1, Name: "Apple iPhone 15 128GB schwarz", URL: "apple-iphone-15-128gb-schwarz-a1.html", Amount: 1, Price: 799, Currency: EUR},
+		{EntityID: 2, Name: "Apple iPhone 15 256GB blau", URL: "apple-iphone-15-256gb-blau-a2.html", Amount: 1, Price: 749, Currency: EUR},
+	}
+
+	if name != "Apple iPhone 15" || price != (Price{Price: 749, Currency: EUR}) || !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("parseComparison() = (%v, %v, %v), want (%v, %v, %v)", name, price, items, "Apple iPhone 15", 749, wantItems)
+	}
+
+	emptyDoc, _ := goquery.NewDocumentFromReader(strings.NewReader("<h1>Vergleich</h1>"))
+	if _, _, _, emptyErr := parseComparison(emptyDoc); !errors.Is(emptyErr, ErrNoFamilyPrices) {
+		t.Errorf("parseComparison() error = %v, want %v", emptyErr, ErrNoFamilyPrices)
+	}
+}
+
+func Test_commonNamePrefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  string
+	}{
+		{name: "Empty", names: nil, want: ""},
+		{name: "Single", names: []string{"Jabra Elite 85t"}, want: "Jabra Elite 85t"},
+		{name: "Common words", names: []string{"Apple iPhone 15 128GB, schwarz", "Apple iPhone 15 256GB, blau"}, want: "Apple iPhone 15"},
+		{name: "Trailing separator", names: []string{"Samsung 990 Pro - 1TB", "Samsung 990 Pro - 2TB"}, want: "Samsung 990 Pro"},
+		{name: "Nothing in common", names: []string{"Apple iPhone 15", "Google Pixel 8"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commonNamePrefix(tt.names); got != tt.want {
+				t.Errorf("commonNamePrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseVariants(t *testing.T) {
+	html := `<div class="variant__variants">
+		<a class="variant__variant" href="apple-iphone-15-128gb-schwarz-a1.html?v=l" title="128GB schwarz"><span class="gh_price">ab € 799,00</span></a>
+		<a class="variant__variant" href="apple-iphone-15-256gb-blau-a2.html"><span class="variant__variant-name">256GB blau</span></a>
+		<a class="variant__variant" href="apple-iphone-15-128gb-schwarz-a1.html" title="Duplikat"></a>
+		<a class="variant__variant" href="/?cat=hand">Kategorie</a>
+	</div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse html: %v", err)
+	}
+
+	want := []SearchResult{
+		{EntityID: 1, Name: "128GB schwarz", URL: "apple-iphone-15-128gb-schwarz-a1.html", Price: Price{Price: 799, Currency: EUR}},
+		{EntityID: 2, Name: "256GB blau", URL: "apple-iphone-15-256gb-blau-a2.html"},
+	}
+
+	if got := parseVariants(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVariants() = %v, want %v", got, want)
+	}
+}
+
+func Test_NormalizeURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawurl string
+		want   string
+	}{
+		{name: "Canonical URL", rawurl: "https://geizhals.de/jabra-elite-85t-a2378831.html", want: "https://geizhals.de/jabra-elite-85t-a2378831.html"},
+		{name: "Whitespace and missing scheme", rawurl: " geizhals.de/a2378831.html ", want: "https://geizhals.de/a2378831.html"},
+		{name: "http and www", rawurl: "http://WWW.Geizhals.de/a2378831.html", want: "https://geizhals.de/a2378831.html"},
+		{name: "Fragment", rawurl: "https://geizhals.de/a2378831.html#offerlist", want: "https://geizhals.de/a2378831.html"},
+		{name: "Tracking parameters only", rawurl: "https://geizhals.de/a2378831.html?utm_source=a&utm_medium=b&gclid=c", want: "https://geizhals.de/a2378831.html"},
+		{name: "Order of other parameters is kept", rawurl: "https://geizhals.de/?xf=1_2&utm_campaign=x&cat=gra16_512", want: "https://geizhals.de/?xf=1_2&cat=gra16_512"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeURL(tt.rawurl); got != tt.want {
+				t.Errorf("NormalizeURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_IsGeizhalsURL(t *testing.T) {
+	tests := []struct {
+		rawurl string
+		want   bool
+	}{
+		{rawurl: "https://geizhals.de/some-short-link", want: true},
+		{rawurl: "www.skinflint.co.uk", want: true},
+		{rawurl: "https://geizhals.de.example.com/a2378831.html", want: false},
+		{rawurl: "https://example.com/?u=geizhals.de", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rawurl, func(t *testing.T) {
+			if got := IsGeizhalsURL(tt.rawurl); got != tt.want {
+				t.Errorf("IsGeizhalsURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_canonicalPath(t *testing.T) {
+	ghURL := EntityURL{Path: "jabra-elite-85t-a2378831.html", Location: "de", EntityID: 2378831, Type: Product}
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "Renamed slug", html: `<link rel="canonical" href="https://geizhals.de/jabra-elite-85t-schwarz-a2378831.html">`, want: "jabra-elite-85t-schwarz-a2378831.html"},
+		{name: "No canonical link", html: `<h1>Jabra</h1>`, want: "jabra-elite-85t-a2378831.html"},
+		{name: "Canonical link to other product", html: `<link rel="canonical" href="https://geizhals.de/other-a1.html">`, want: "jabra-elite-85t-a2378831.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("failed to parse html: %v", err)
+			}
+
+			if got := canonicalPath(ghURL, doc); got != tt.want {
+				t.Errorf("canonicalPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveURL(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<link rel="canonical" href="https://geizhals.de/jabra-elite-85t-a2378831.html">`))
+	if err != nil {
+		t.Fatalf("failed to parse html: %v", err)
+	}
+
+	got, resolveErr := resolveURL("https://geizhals.de/s/abc", doc)
+	if resolveErr != nil {
+		t.Fatalf("resolveURL() error = %v", resolveErr)
+	}
+
+	if got.EntityID != 2378831 || got.Path != "jabra-elite-85t-a2378831.html" || got.SubmittedURL != "https://geizhals.de/s/abc" {
+		t.Errorf("resolveURL() = %v", got)
+	}
+
+	emptyDoc, _ := goquery.NewDocumentFromReader(strings.NewReader("<h1>Startseite</h1>"))
+	if _, emptyErr := resolveURL("https://geizhals.de/s/abc", emptyDoc); !errors.Is(emptyErr, ErrInvalidURL) {
+		t.Errorf("resolveURL() error = %v, want %v", emptyErr, ErrInvalidURL)
+	}
+}
+
+func Test_OutcomeFromError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ScrapeOutcome
+	}{
+		{name: "Success", err: nil, want: OutcomeSuccess},
+		{name: "Delisted", err: fmt.Errorf("error for http request: %w", ErrEntityNotFound), want: OutcomeUnavailable},
+		{name: "No offers", err: ErrNoOffers, want: OutcomeUnavailable},
+		{name: "Selector missing", err: fmt.Errorf("parseProduct: product name: %w", ErrSelectorMissing), want: OutcomeSelectorMissing},
+		{name: "Price unparsable", err: ErrPriceUnparsable, want: OutcomePriceUnparsable},
+		{name: "HTTP error", err: errors.New("error for http request"), want: OutcomeHTTPError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OutcomeFromError(tt.err); got != tt.want {
+				t.Errorf("OutcomeFromError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseProductErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want ScrapeOutcome
+	}{
+		{name: "Name missing", html: `<div id="offer__price-0"><span class="gh_price">€ 12,34</span></div>`, want: OutcomeSelectorMissing},
+		{name: "No offers", html: `<div class="variant__header"><h1>Produkt</h1></div>`, want: OutcomeUnavailable},
+		{name: "Price unparsable", html: `<div class="variant__header"><h1>Produkt</h1></div><div id="offer__price-0"><span class="gh_price">auf Anfrage</span></div>`, want: OutcomePriceUnparsable},
+		{name: "Success", html: `<div class="variant__header"><h1>Produkt</h1></div><div id="offer__price-0"><span class="gh_price">€ 12,34</span></div>`, want: OutcomeSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("failed to parse html: %v", err)
+			}
+
+			if _, _, parseErr := parseProduct(doc); OutcomeFromError(parseErr) != tt.want {
+				t.Errorf("parseProduct() error = %v, want outcome %v", parseErr, tt.want)
+			}
+		})
+	}
+}
+
+func Test_scrapeHealth(t *testing.T) {
+	var h scrapeHealth
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	window := time.Hour
+
+	h.record(OutcomeHTTPError, now.Add(-2*time.Hour), window)
+	h.record(OutcomeSuccess, now.Add(-30*time.Minute), window)
+	h.record(OutcomeSelectorMissing, now.Add(-20*time.Minute), window)
+	h.record(OutcomeUnavailable, now.Add(-10*time.Minute), window)
+	h.record(OutcomeSuccess, now, window)
+
+	stats := h.stats(now, window)
+	if stats.Total != 4 || stats.Failures() != 1 || stats.FailureRate() != 0.25 {
+		t.Errorf("stats() = %d total, %d failures, rate %v, want 4 total, 1 failure, rate 0.25", stats.Total, stats.Failures(), stats.FailureRate())
+	}
+
+	// Outcomes leave the window over time
+	stats = h.stats(now.Add(45*time.Minute), window)
+	if stats.Total != 2 || stats.Failures() != 0 {
+		t.Errorf("stats() = %d total, %d failures, want 2 total, 0 failures", stats.Total, stats.Failures())
+	}
+}
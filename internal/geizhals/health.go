@@ -0,0 +1,152 @@
+package geizhals
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/config"
+	"github.com/d-Rickyy-b/gogeizhalsbot/v2/internal/prometheus"
+)
+
+// ScrapeOutcome classifies the result of downloading and parsing the page of an entity
+type ScrapeOutcome string
+
+const (
+	OutcomeSuccess ScrapeOutcome = "success"
+	// OutcomeUnavailable means that the page was parsed, but the entity is delisted or has no offers
+	OutcomeUnavailable     ScrapeOutcome = "unavailable"
+	OutcomeHTTPError       ScrapeOutcome = "http_error"
+	OutcomeSelectorMissing ScrapeOutcome = "selector_missing"
+	OutcomePriceUnparsable ScrapeOutcome = "price_unparsable"
+)
+
+var (
+	ErrSelectorMissing = errors.New("element not found on page")
+	ErrPriceUnparsable = errors.New("could not parse price")
+)
+
+// IsFailure checks if the outcome indicates a problem of the scraper, e.g. because Geizhals changed its layout.
+func (o ScrapeOutcome) IsFailure() bool {
+	return o == OutcomeHTTPError || o == OutcomeSelectorMissing || o == OutcomePriceUnparsable
+}
+
+// OutcomeFromError classifies the error of downloading and parsing an entity. Errors which are neither caused by
+// parsing nor by the availability of the entity are caused by the download.
+func OutcomeFromError(err error) ScrapeOutcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case StatusFromError(err).IsGone():
+		return OutcomeUnavailable
+	case errors.Is(err, ErrSelectorMissing):
+		return OutcomeSelectorMissing
+	case errors.Is(err, ErrPriceUnparsable):
+		return OutcomePriceUnparsable
+	}
+
+	return OutcomeHTTPError
+}
+
+// ScrapeStats summarizes the outcomes of the scrapes within the health window.
+type ScrapeStats struct {
+	Window   time.Duration
+	Total    int
+	Outcomes map[ScrapeOutcome]int
+}
+
+// Failures returns the number of failed scrapes.
+func (s ScrapeStats) Failures() int {
+	failures := 0
+
+	for outcome, count := range s.Outcomes {
+		if outcome.IsFailure() {
+			failures += count
+		}
+	}
+
+	return failures
+}
+
+// FailureRate returns the share of failed scrapes between 0 and 1.
+func (s ScrapeStats) FailureRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+
+	return float64(s.Failures()) / float64(s.Total)
+}
+
+type scrapeResult struct {
+	time    time.Time
+	outcome ScrapeOutcome
+}
+
+// scrapeHealth keeps the outcomes of the recent scrapes to calculate a rolling failure rate.
+type scrapeHealth struct {
+	mu      sync.Mutex
+	results []scrapeResult
+}
+
+var health scrapeHealth
+
+// record adds the outcome of a scrape and drops the outcomes which are older than the window.
+func (h *scrapeHealth) record(outcome ScrapeOutcome, now time.Time, window time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(now, window)
+	h.results = append(h.results, scrapeResult{time: now, outcome: outcome})
+}
+
+// stats summarizes the outcomes within the window.
+func (h *scrapeHealth) stats(now time.Time, window time.Duration) ScrapeStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(now, window)
+
+	stats := ScrapeStats{Window: window, Total: len(h.results), Outcomes: make(map[ScrapeOutcome]int)}
+	for _, result := range h.results {
+		stats.Outcomes[result.outcome]++
+	}
+
+	return stats
+}
+
+// prune drops the outcomes which are older than the window. The results are sorted by time.
+func (h *scrapeHealth) prune(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+
+	i := 0
+	for i < len(h.results) && h.results[i].time.Before(cutoff) {
+		i++
+	}
+
+	h.results = h.results[i:]
+}
+
+// recordScrape counts the outcome of downloading and parsing an entity of the given type and location.
+func recordScrape(entityType EntityType, location string, err error) {
+	outcome := OutcomeFromError(err)
+	prometheus.ScrapeResults(string(outcome), entityType.String(), location).Inc()
+
+	health.record(outcome, time.Now(), healthWindow())
+}
+
+// GetScrapeStats returns the outcomes of the scrapes within the configured health window.
+func GetScrapeStats() ScrapeStats {
+	return health.stats(time.Now(), healthWindow())
+}
+
+// healthWindow returns the duration of the window for the scraper health check from the config.
+func healthWindow() time.Duration {
+	conf, err := config.GetConfig()
+	if err != nil {
+		log.Println("Error while reading config file: ", err)
+		return time.Hour
+	}
+
+	return time.Duration(conf.ScraperHealth.WindowMinutes) * time.Minute
+}
@@ -26,7 +26,7 @@ func parsePrice(priceString string) (Price, error) {
 	case strings.Contains(priceString, "PLN"):
 		currency = PLN
 	default:
-		return Price{}, fmt.Errorf("could not parse price")
+		return Price{}, ErrPriceUnparsable
 	}
 
 	priceString = strings.ReplaceAll(priceString, ",", ".")
@@ -37,7 +37,7 @@ func parsePrice(priceString string) (Price, error) {
 	price, err := strconv.ParseFloat(priceString, 64)
 	if err != nil {
 		log.Printf("Can't parse price: '%s' - %s", priceString, err)
-		return Price{}, fmt.Errorf("could not parse price: %w", err)
+		return Price{}, fmt.Errorf("%w: %w", ErrPriceUnparsable, err)
 	}
 
 	return Price{Price: price, Currency: currency}, nil
@@ -61,8 +61,14 @@ func parseEntity(ghURL EntityURL, doc *goquery.Document) (Entity, error) {
 	switch ghURL.Type {
 	case Product:
 		name, price, parseErr = parseProduct(doc)
+		entity.EAN, entity.MPN = parseProductCodes(doc)
 	case Wishlist:
 		name, price, parseErr = parseWishlist(doc)
+		entity.Items = parseWishlistItems(doc)
+	case Category:
+		name, price, entity.Items, parseErr = parseCategory(doc)
+	case ProductFamily:
+		name, price, entity.Items, parseErr = parseComparison(doc)
 	default:
 		log.Printf("Invalid entityType '%v'\n", ghURL.Type)
 		return entity, fmt.Errorf("invalid entityType")
@@ -73,6 +79,7 @@ func parseEntity(ghURL EntityURL, doc *goquery.Document) (Entity, error) {
 	}
 
 	entity.Name = name
+	entity.URL = canonicalPath(ghURL, doc)
 	entity.Prices = []EntityPrice{{
 		EntityID: entity.ID,
 		Price:    price.Price,
@@ -80,6 +87,11 @@ func parseEntity(ghURL EntityURL, doc *goquery.Document) (Entity, error) {
 		Location: ghURL.Location,
 	}}
 
+	for i := range entity.Items {
+		entity.Items[i].WishlistID = entity.ID
+		entity.Items[i].Location = ghURL.Location
+	}
+
 	return entity, nil
 }
 
@@ -91,6 +103,9 @@ func parseWishlist(doc *goquery.Document) (string, Price, error) {
 
 	// Parse price from html
 	priceString := doc.Find(".wishlist-bottom span.wishlist-sum:nth-child(2)").Text()
+	if strings.TrimSpace(priceString) == "" {
+		return "", Price{}, fmt.Errorf("parseWishlist: wishlist sum: %w", ErrSelectorMissing)
+	}
 
 	price, parseErr := parsePrice(priceString)
 	if parseErr != nil {
@@ -105,9 +120,15 @@ func parseProduct(doc *goquery.Document) (string, Price, error) {
 	// parse name from html
 	name := doc.Find("div.variant__header h1").Text()
 	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", Price{}, fmt.Errorf("parseProduct: product name: %w", ErrSelectorMissing)
+	}
 
 	// Parse price from html
 	priceString := doc.Find("div#offer__price-0 span.gh_price").Text()
+	if strings.TrimSpace(priceString) == "" {
+		return name, Price{}, ErrNoOffers
+	}
 
 	price, parseErr := parsePrice(priceString)
 	if parseErr != nil {
@@ -117,9 +138,11 @@ func parseProduct(doc *goquery.Document) (string, Price, error) {
 	return name, price, nil
 }
 
-// parseWishlistEntityIDsAndAmounts parses the wishlist entity IDs and the amount of the entity in the wishlist
-// from the given HTML document.
-func parseWishlistEntityIDsAndAmounts(doc *goquery.Document) (entityIDs []int64, amounts []int64, parseErr error) {
+// parseWishlistItems parses the products contained in a wishlist including their amount and unit price
+// from the given HTML document. Items without a parsable price are returned with a price of 0.
+func parseWishlistItems(doc *goquery.Document) []WishlistItem {
+	var items []WishlistItem
+
 	// get wishlist__item and iterate over all of them
 	wishlistItems := doc.Find("div.wishlist__item")
 	wishlistItems.Each(func(i int, selection *goquery.Selection) {
@@ -143,9 +166,33 @@ func parseWishlistEntityIDsAndAmounts(doc *goquery.Document) (entityIDs []int64,
 			return
 		}
 
-		entityIDs = append(entityIDs, entityID)
-		amounts = append(amounts, amount)
+		item := WishlistItem{EntityID: entityID, Amount: amount}
+
+		nameLink := selection.Find("a.wishlist__item-name").First()
+		item.Name = strings.TrimSpace(nameLink.Text())
+		if href, hrefExists := nameLink.Attr("href"); hrefExists {
+			item.URL = productPathPattern.FindString(href)
+		}
+
+		price, priceErr := parsePrice(strings.TrimSpace(selection.Find("span.gh_price").First().Text()))
+		if priceErr == nil {
+			item.Price = price.Price
+			item.Currency = price.Currency
+		}
+
+		items = append(items, item)
 	})
 
+	return items
+}
+
+// parseWishlistEntityIDsAndAmounts parses the wishlist entity IDs and the amount of the entity in the wishlist
+// from the given HTML document.
+func parseWishlistEntityIDsAndAmounts(doc *goquery.Document) (entityIDs []int64, amounts []int64, parseErr error) {
+	for _, item := range parseWishlistItems(doc) {
+		entityIDs = append(entityIDs, item.EntityID)
+		amounts = append(amounts, item.Amount)
+	}
+
 	return entityIDs, amounts, parseErr
 }
@@ -39,8 +39,14 @@ type PriceEntry struct {
 	Valid     bool      `json:"valid"`
 }
 
+// priceHistoryCacheKey identifies the price history of an entity for a location
+type priceHistoryCacheKey struct {
+	EntityID int64
+	Location string
+}
+
 var (
-	userCache  = make(map[int64]PriceHistory)
+	userCache  = make(map[priceHistoryCacheKey]PriceHistory)
 	cacheMutex sync.Mutex
 )
 
@@ -83,7 +89,7 @@ func (entry *PriceEntry) UnmarshalJSON(p []byte) error {
 // GetPriceHistory returns the price history for the given entity either from cache or by downloading it.
 func GetPriceHistory(entity Entity, location string) (PriceHistory, error) {
 	// Check if we already have the price history in cache
-	history, isCached := getPriceHistoryFromCache(entity)
+	history, isCached := getPriceHistoryFromCache(entity, location)
 	if isCached {
 		return history, nil
 	}
@@ -91,19 +97,20 @@ func GetPriceHistory(entity Entity, location string) (PriceHistory, error) {
 	return getPriceHistory(entity, location)
 }
 
-// getPriceHistoryFromCache returns the price history for the given entity from cache, if it is cached.
-func getPriceHistoryFromCache(entity Entity) (PriceHistory, bool) {
+// getPriceHistoryFromCache returns the price history for the given entity and location from cache, if it is cached.
+func getPriceHistoryFromCache(entity Entity, location string) (PriceHistory, bool) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
-	if priceHistory, ok := userCache[entity.ID]; ok {
+	cacheKey := priceHistoryCacheKey{EntityID: entity.ID, Location: location}
+	if priceHistory, ok := userCache[cacheKey]; ok {
 		// Check if the price history is still valid
 		if time.Since(priceHistory.Meta.DownloadedAt) < 12*time.Hour {
 			log.Printf("Using cached price history for '%s'\n", entity.Name)
 			return priceHistory, true
 		}
 
-		delete(userCache, entity.ID)
+		delete(userCache, cacheKey)
 	}
 
 	return PriceHistory{}, false
@@ -113,6 +120,7 @@ func getPriceHistoryFromCache(entity Entity) (PriceHistory, bool) {
 // For products, this is just the product ID and 1.
 // For wishlists, this is the product IDs and amounts of the products contained in the wishlist.
 // For wishlists an HTML download is required to obtain all the entity IDs and amounts.
+// Category listings don't have a price history.
 func getEntityIDsAndAmounts(entity Entity, location string) ([]int64, []int64, error) {
 	var entityIDs []int64
 	var amounts []int64
@@ -134,6 +142,8 @@ func getEntityIDsAndAmounts(entity Entity, location string) ([]int64, []int64, e
 			log.Println("Error parsing wishlist entities:", parseErr)
 			return nil, nil, downloadErr
 		}
+	case Category, ProductFamily:
+		return nil, nil, ErrNoPriceHistory
 	}
 
 	return entityIDs, amounts, nil
@@ -158,7 +168,7 @@ func getPriceHistory(entity Entity, location string) (PriceHistory, error) {
 	// Cache the price history
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
-	userCache[entity.ID] = pricehistory
+	userCache[priceHistoryCacheKey{EntityID: entity.ID, Location: location}] = pricehistory
 
 	return pricehistory, nil
 }
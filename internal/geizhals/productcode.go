@@ -0,0 +1,69 @@
+package geizhals
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	eanPattern = regexp.MustCompile(`EAN\s*:\s*(\d{8,14})`)
+	mpnPattern = regexp.MustCompile(`(?:Herstellernummer|Herst\.\s*Art\.\s*Nr\.|Herst\.-Nr\.|MPN|Part Number)\s*:\s*([^\s,;]+)`)
+	gtinLength = map[int]bool{8: true, 12: true, 13: true, 14: true}
+)
+
+// parseProductCodes parses the EAN and the manufacturer part number (MPN) from the geizhals product page.
+// Products with several EANs or MPNs only return the first one. Codes which can't be found are returned empty.
+func parseProductCodes(doc *goquery.Document) (ean, mpn string) {
+	codes := doc.Find("div.variant__header__codes").Text()
+	if strings.TrimSpace(codes) == "" {
+		codes = doc.Find("div.variant__header").Text()
+	}
+
+	if matches := eanPattern.FindStringSubmatch(codes); len(matches) == 2 && IsValidGTIN(matches[1]) {
+		ean = matches[1]
+	}
+
+	if matches := mpnPattern.FindStringSubmatch(codes); len(matches) == 2 {
+		mpn = matches[1]
+	}
+
+	return ean, mpn
+}
+
+// HasGTINLength checks if the given code has the length of a GTIN-8, GTIN-12 (UPC), GTIN-13 (EAN) or GTIN-14.
+func HasGTINLength(code string) bool {
+	return gtinLength[len(code)]
+}
+
+// IsValidGTIN checks if the given code is a valid GTIN-8, GTIN-12 (UPC), GTIN-13 (EAN) or GTIN-14 including its check digit.
+func IsValidGTIN(code string) bool {
+	if !HasGTINLength(code) {
+		return false
+	}
+
+	sum := 0
+
+	// The check digit is the last digit. Starting from the right, the other digits are weighted 3, 1, 3, ...
+	for i := len(code) - 1; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+
+		if i == len(code)-1 {
+			continue
+		}
+
+		if (len(code)-1-i)%2 == 1 {
+			sum += digit * 3
+		} else {
+			sum += digit
+		}
+	}
+
+	checkDigit := (10 - sum%10) % 10
+
+	return checkDigit == int(code[len(code)-1]-'0')
+}
@@ -0,0 +1,123 @@
+package geizhals
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var ErrEmptySearchQuery = errors.New("empty search query")
+
+// SearchResult represents a single product found on the Geizhals search results page.
+type SearchResult struct {
+	EntityID int64
+	Name     string
+	URL      string
+	Price    Price
+}
+
+// FullURL returns the URL of the product page of the search result for the given location.
+func (s SearchResult) FullURL(location string) string {
+	domain, ok := geizhalsDomains[location]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("https://%s/%s", domain, s.URL)
+}
+
+// ProductURL returns the short URL of the product with the given ID for the given location.
+func ProductURL(entityID int64, location string) string {
+	domain, ok := geizhalsDomains[location]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("https://%s/a%d.html", domain, entityID)
+}
+
+// SearchURL returns the URL of the Geizhals search results page for the given query and location.
+func SearchURL(query, location string) string {
+	domain, ok := geizhalsDomains[location]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("https://%s/?fs=%s", domain, url.QueryEscape(query))
+}
+
+// Search queries the Geizhals search for the given query and returns at most limit products of the first result page.
+func Search(query, location string, limit int) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	searchURL := SearchURL(query, location)
+	if searchURL == "" {
+		return nil, fmt.Errorf("Search: invalid location '%s'", location)
+	}
+
+	doc, downloadErr := downloadDocument(searchURL)
+	if downloadErr != nil {
+		return nil, downloadErr
+	}
+
+	results := parseSearchResults(doc)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// parseSearchResults parses the products of the Geizhals search results page from the given HTML document.
+// Results without a valid product link are skipped, results without a price are returned with a price of 0.
+func parseSearchResults(doc *goquery.Document) []SearchResult {
+	var results []SearchResult
+
+	seen := make(map[int64]bool)
+
+	doc.Find("article.listview__item").Each(func(i int, selection *goquery.Selection) {
+		nameLink := selection.Find("a.listview__name-link").First()
+
+		href, hrefExists := nameLink.Attr("href")
+		if !hrefExists {
+			return
+		}
+
+		matches := productPathPattern.FindStringSubmatch(href)
+		if len(matches) != 2 {
+			return
+		}
+
+		entityID, convertErr := strconv.ParseInt(matches[1], 10, 0)
+		if convertErr != nil || seen[entityID] {
+			return
+		}
+		seen[entityID] = true
+
+		result := SearchResult{
+			EntityID: entityID,
+			Name:     strings.TrimSpace(nameLink.Text()),
+			URL:      matches[0],
+		}
+
+		// Prices on the search results page are prefixed with "ab" (from)
+		priceString := strings.TrimSpace(selection.Find("span.price").First().Text())
+		priceString = strings.TrimSpace(strings.TrimPrefix(priceString, "ab"))
+
+		price, priceErr := parsePrice(priceString)
+		if priceErr == nil {
+			result.Price = price
+		}
+
+		results = append(results, result)
+	})
+
+	return results
+}
@@ -0,0 +1,59 @@
+package geizhals
+
+import (
+	"errors"
+)
+
+// EntityStatus describes the availability of an entity at a location as of the last check.
+type EntityStatus int
+
+const (
+	// StatusActive is the default for entities which were checked successfully, including entities stored before
+	// the status was tracked
+	StatusActive EntityStatus = 0
+	// StatusNoOffers means that the page of the entity exists, but no shop offers it
+	StatusNoOffers EntityStatus = 1
+	// StatusDelisted means that Geizhals removed the page of the entity
+	StatusDelisted EntityStatus = 2
+	// StatusError means that the last check failed for another reason, e.g. a network error
+	StatusError EntityStatus = 3
+)
+
+var (
+	ErrEntityNotFound = errors.New("entity not found on Geizhals")
+	ErrNoOffers       = errors.New("no offers for entity")
+)
+
+func (s EntityStatus) String() string {
+	switch s {
+	case StatusActive:
+		return "aktiv"
+	case StatusNoOffers:
+		return "keine Angebote"
+	case StatusDelisted:
+		return "nicht mehr gelistet"
+	case StatusError:
+		return "Fehler"
+	}
+
+	return ""
+}
+
+// IsGone checks if the entity is currently not available, because it was delisted or has no offers.
+func (s EntityStatus) IsGone() bool {
+	return s == StatusNoOffers || s == StatusDelisted
+}
+
+// StatusFromError determines the status of an entity from the error of downloading it.
+func StatusFromError(err error) EntityStatus {
+	switch {
+	case err == nil:
+		return StatusActive
+	case errors.Is(err, ErrEntityNotFound):
+		return StatusDelisted
+	case errors.Is(err, ErrNoOffers), errors.Is(err, ErrNoCategoryProducts), errors.Is(err, ErrNoFamilyPrices):
+		return StatusNoOffers
+	}
+
+	return StatusError
+}
@@ -19,19 +19,22 @@ type EntityURL struct {
 }
 
 // parseGeizhalsURL parses the given URL and returns a EntityURL struct.
+// The URL is normalized first, so that e.g. "www." prefixes and tracking parameters don't matter.
 func parseGeizhalsURL(rawurl string) (EntityURL, error) {
 	var matches []string
 	var location string
 	entityType := Product
 
-	matches = productURLPattern.FindStringSubmatch(rawurl)
+	normalizedURL := NormalizeURL(rawurl)
+
+	matches = productURLPattern.FindStringSubmatch(normalizedURL)
 	if len(matches) != 4 {
-		matches = wishlistURLPattern.FindStringSubmatch(rawurl)
+		matches = wishlistURLPattern.FindStringSubmatch(normalizedURL)
 		entityType = Wishlist
 	}
 
 	if len(matches) != 4 {
-		return EntityURL{}, ErrInvalidURL
+		return parseQueryURL(rawurl, normalizedURL)
 	}
 
 	entityIDString := matches[3]
@@ -47,7 +50,7 @@ func parseGeizhalsURL(rawurl string) (EntityURL, error) {
 	}
 
 	// Pick location from the domain name
-	locationTLDMatches := locationDomainPattern.FindStringSubmatch(rawurl)
+	locationTLDMatches := locationDomainPattern.FindStringSubmatch(normalizedURL)
 	for i, locationTLDMatch := range locationTLDMatches {
 		if locationTLDMatch == "" || i == 0 {
 			continue
@@ -74,6 +77,25 @@ func parseGeizhalsURL(rawurl string) (EntityURL, error) {
 	return ghURL, nil
 }
 
+// parseQueryURL parses URLs consisting of the domain and a query, i.e. comparisons, legacy product links and categories.
+func parseQueryURL(rawurl, normalizedURL string) (EntityURL, error) {
+	parsers := []func(string) (EntityURL, error){parseComparisonURL, parseLegacyProductURL, parseCategoryURL}
+
+	var parseErr error
+
+	for _, parser := range parsers {
+		var ghURL EntityURL
+
+		ghURL, parseErr = parser(normalizedURL)
+		if parseErr == nil {
+			ghURL.SubmittedURL = rawurl
+			return ghURL, nil
+		}
+	}
+
+	return EntityURL{}, parseErr
+}
+
 func LocationFromURL(rawurl string) (string, error) {
 	locationTLDMatches := locationDomainPattern.FindStringSubmatch(rawurl)
 	for i, locationTLDMatch := range locationTLDMatches {
@@ -86,3 +108,29 @@ func LocationFromURL(rawurl string) (string, error) {
 
 	return "", errors.New("couldn't parse location")
 }
+
+// FindEntityURLs returns all the URLs to Geizhals products, wishlists, categories or comparisons contained in the given text.
+// Geizhals URLs of unknown shape, e.g. short links, are kept as well, so that they can be resolved when downloading them.
+// The URLs are returned in the order of their occurrence, duplicates are removed.
+func FindEntityURLs(text string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	for _, candidate := range entityURLSearchPattern.FindAllString(text, -1) {
+		key := NormalizeURL(candidate)
+		if ghURL, parseErr := parseGeizhalsURL(candidate); parseErr == nil {
+			key = ghURL.CleanURL
+		} else if !IsGeizhalsURL(candidate) {
+			continue
+		}
+
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		urls = append(urls, candidate)
+	}
+
+	return urls
+}
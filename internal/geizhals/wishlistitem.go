@@ -0,0 +1,125 @@
+package geizhals
+
+import (
+	"fmt"
+	"time"
+)
+
+// WishlistItem represents a single product contained in a wishlist for a given location.
+type WishlistItem struct {
+	ID         int64
+	WishlistID int64  `gorm:"not null;index:wishlist_item_idx"`
+	Location   string `gorm:"not null;index:wishlist_item_idx"`
+	EntityID   int64  `gorm:"not null;"`
+	UpdatedAt  time.Time
+	Name       string
+	URL        string
+	Amount     int64    `gorm:"not null;default:1"`
+	Price      float64  `gorm:"not null;default:0"`
+	Currency   Currency `gorm:"not null;default:1"`
+}
+
+// Subtotal returns the price of the item multiplied by the amount contained in the wishlist.
+func (w WishlistItem) Subtotal() float64 {
+	return w.Price * float64(w.Amount)
+}
+
+// FullURL returns the URL of the product page of the item for the given location.
+// If the path of the product is unknown, the short product URL is used.
+func (w WishlistItem) FullURL(location string) string {
+	domain, ok := geizhalsDomains[location]
+	if !ok {
+		return ""
+	}
+
+	if w.URL == "" {
+		return ProductURL(w.EntityID, location)
+	}
+
+	return fmt.Sprintf("https://%s/%s", domain, w.URL)
+}
+
+// WishlistChangeType describes how an item of a wishlist changed between two checks.
+type WishlistChangeType int
+
+const (
+	// ItemPriceChanged means that the unit price of an item changed
+	ItemPriceChanged WishlistChangeType = iota + 1
+	// ItemAdded means that an item was added to the wishlist
+	ItemAdded
+	// ItemRemoved means that an item was removed from the wishlist
+	ItemRemoved
+	// ItemAmountChanged means that the amount of an item in the wishlist changed
+	ItemAmountChanged
+)
+
+// WishlistItemChange describes the change of a single item between two checks of a wishlist.
+// For removed items, Item contains the item as it was stored during the previous check.
+type WishlistItemChange struct {
+	Type      WishlistChangeType
+	Item      WishlistItem
+	OldPrice  float64
+	OldAmount int64
+}
+
+// IsCompositionChange returns true if the item was added, removed or its amount changed, i.e. the owner of the
+// wishlist changed its contents, as opposed to a price change on Geizhals.
+func (c WishlistItemChange) IsCompositionChange() bool {
+	return c.Type == ItemAdded || c.Type == ItemRemoved || c.Type == ItemAmountChanged
+}
+
+// Difference returns how much the subtotal of the item changed.
+func (c WishlistItemChange) Difference() float64 {
+	if c.Type == ItemRemoved {
+		return -c.Item.Subtotal()
+	}
+
+	return c.Item.Subtotal() - c.OldPrice*float64(c.OldAmount)
+}
+
+// DiffWishlistItems compares the items of two checks of the same wishlist and returns the items which were added,
+// removed, whose amount changed or whose unit price changed.
+func DiffWishlistItems(oldItems, newItems []WishlistItem) []WishlistItemChange {
+	oldItemsByID := make(map[int64]WishlistItem, len(oldItems))
+	for _, item := range oldItems {
+		oldItemsByID[item.EntityID] = item
+	}
+
+	var changes []WishlistItemChange
+
+	newItemIDs := make(map[int64]bool, len(newItems))
+
+	for _, item := range newItems {
+		newItemIDs[item.EntityID] = true
+
+		oldItem, ok := oldItemsByID[item.EntityID]
+
+		switch {
+		case !ok:
+			changes = append(changes, WishlistItemChange{Type: ItemAdded, Item: item})
+		case oldItem.Amount != item.Amount:
+			changes = append(changes, WishlistItemChange{Type: ItemAmountChanged, Item: item, OldPrice: oldItem.Price, OldAmount: oldItem.Amount})
+		case oldItem.Price != item.Price:
+			changes = append(changes, WishlistItemChange{Type: ItemPriceChanged, Item: item, OldPrice: oldItem.Price, OldAmount: oldItem.Amount})
+		}
+	}
+
+	for _, item := range oldItems {
+		if !newItemIDs[item.EntityID] {
+			changes = append(changes, WishlistItemChange{Type: ItemRemoved, Item: item, OldPrice: item.Price, OldAmount: item.Amount})
+		}
+	}
+
+	return changes
+}
+
+// HasCompositionChange returns true if any of the given changes is a composition change.
+func HasCompositionChange(changes []WishlistItemChange) bool {
+	for _, change := range changes {
+		if change.IsCompositionChange() {
+			return true
+		}
+	}
+
+	return false
+}
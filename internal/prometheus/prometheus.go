@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
@@ -34,8 +35,20 @@ var (
 	PriceagentNotifications = metrics.NewCounter("gogeizhalsbot_priceagent_notifications_total")
 	HTTPErrors              = metrics.NewCounter("gogeizhalsbot_http_errors_total")
 	GraphsRendered          = metrics.NewCounter("gogeizhalsbot_graphs_rendered_total")
+	// ScrapeFailureRate is the share of failed scrapes within the window of the scraper health check
+	ScrapeFailureRate = metrics.NewGauge("gogeizhalsbot_scrape_failure_rate", nil)
 )
 
+// StaleButtons returns the counter of stale inline buttons pressed by users for the given failure category
+func StaleButtons(category string) *metrics.Counter {
+	return metrics.GetOrCreateCounter(fmt.Sprintf("gogeizhalsbot_stale_buttons_total{category=%q}", category))
+}
+
+// ScrapeResults returns the counter of downloaded and parsed Geizhals pages for the given outcome, entity type and location
+func ScrapeResults(outcome, entityType, location string) *metrics.Counter {
+	return metrics.GetOrCreateCounter(fmt.Sprintf("gogeizhalsbot_scrapes_total{outcome=%q,type=%q,location=%q}", outcome, entityType, location))
+}
+
 // var backgroundUpdateChecks = metrics.NewSummary("gogeizhalsbot_total_requests")
 
 func StartPrometheusExporter(addr string) error {